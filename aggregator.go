@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"internal/database"
+	"internal/rss"
+	"math"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// Interval given to feeds without one of their own when fetching outside agg.
+	defaultFetchInterval = time.Hour
+	defaultMaxFailures   = 10
+
+	minFetchInterval = time.Minute
+	maxFetchInterval = 24 * time.Hour
+	maxBackoff       = 7 * 24 * time.Hour
+
+	// Delay before retrying a feed whose host already has every request slot taken.
+	busyHostDelay = 10 * time.Second
+)
+
+// errHostUnavailable is returned for feeds deferred because their host is busy or paused.
+var errHostUnavailable = errors.New("host unavailable")
+
+type aggregator struct {
+	s           *state
+	interval    time.Duration
+	maxFailures int
+	hosts       *hostLimiter
+
+	statsMu sync.Mutex
+	stats   aggregatorStats
+}
+
+type aggregatorStats struct {
+	fetched  int
+	failed   int
+	created  int
+	updated  int
+	skipped  int
+	rejected int
+}
+
+func newAggregator(s *state, interval time.Duration, perHost int, maxFailures int) *aggregator {
+	return &aggregator{
+		s:           s,
+		interval:    interval,
+		maxFailures: maxFailures,
+		hosts:       newHostLimiter(perHost),
+	}
+}
+
+// run returns once ctx is cancelled and every worker has finished or aborted its current feed.
+func (a *aggregator) run(ctx context.Context, workers int) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (a *aggregator) work(ctx context.Context) {
+	idle := time.NewTicker(min(a.interval, 30*time.Second))
+	defer idle.Stop()
+
+	for ctx.Err() == nil {
+		dbFeed, err := a.claimNextFeed(ctx)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) && ctx.Err() == nil {
+				fmt.Println(err)
+			}
+			select {
+			case <-ctx.Done():
+			case <-idle.C:
+			}
+			continue
+		}
+
+		a.fetchFeed(ctx, dbFeed)
+	}
+}
+
+// fetchFeed scrapes a claimed feed and updates its failure tracking and schedule.
+// The returned error is the fetch error; bookkeeping errors are only reported.
+// Feeds whose host is busy or paused are deferred rather than waited for, so a
+// slow or rate-limiting host never holds up the workers.
+func (a *aggregator) fetchFeed(ctx context.Context, dbFeed database.Feed) (scrapeResult, error) {
+	host := feedHost(dbFeed.Url)
+	if wait, ok := a.hosts.tryAcquire(host); !ok {
+		a.deferFeed(ctx, dbFeed, host, wait)
+		return scrapeResult{}, errHostUnavailable
+	}
+	scraped, fetchErr := scrapeFeeds(ctx, a.s, dbFeed)
+	a.hosts.release(host)
+
+	// A fetch aborted by shutdown is not the feed's fault, and the claim already
+	// scheduled it for a later run.
+	if ctx.Err() != nil {
+		fmt.Printf("Aborted fetching feed '%s'\n", dbFeed.Name)
+		return scraped, ctx.Err()
+	}
+
+	a.count(scraped, fetchErr)
+
+	var err error
+	if fetchErr != nil {
+		a.reportFetchError(host, fetchErr)
+		err = a.recordFailure(ctx, dbFeed, fetchErr)
+	} else {
+		err = a.recordSuccess(ctx, dbFeed, scraped)
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	return scraped, fetchErr
+}
+
+// deferFeed reschedules a feed for when its host is expected to take requests
+// again: after the pause announced by the host, or shortly when it is only busy.
+func (a *aggregator) deferFeed(ctx context.Context, dbFeed database.Feed, host string, wait time.Duration) {
+	if wait > 0 {
+		fmt.Printf("Deferring feed '%s' while requests to %s are paused for %s\n", dbFeed.Name, host, wait.Round(time.Second))
+	}
+
+	now := time.Now()
+	err := a.s.db.UpdateFeedSchedule(ctx,
+		database.UpdateFeedScheduleParams{
+			ID:            dbFeed.ID,
+			UpdatedAt:     now,
+			NextFetchAt:   sql.NullTime{Time: now.Add(max(wait, min(a.interval, busyHostDelay))), Valid: true},
+			FetchInterval: dbFeed.FetchInterval,
+		})
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func (a *aggregator) count(scraped scrapeResult, err error) {
+	a.statsMu.Lock()
+	defer a.statsMu.Unlock()
+
+	a.stats.fetched++
+	if err != nil {
+		a.stats.failed++
+	}
+	a.stats.created += scraped.created
+	a.stats.updated += scraped.updated
+	a.stats.skipped += scraped.skipped
+	a.stats.rejected += len(scraped.rejected)
+}
+
+func (a *aggregator) failures() int {
+	a.statsMu.Lock()
+	defer a.statsMu.Unlock()
+
+	return a.stats.failed
+}
+
+func (a *aggregator) printSummary() {
+	a.statsMu.Lock()
+	defer a.statsMu.Unlock()
+
+	fmt.Printf("Fetched %d feeds (%d failed): %d new posts, %d updated, %d skipped, %d rejected\n",
+		a.stats.fetched, a.stats.failed, a.stats.created, a.stats.updated, a.stats.skipped, a.stats.rejected)
+}
+
+// Claiming is a single statement that skips rows locked by other claims, so any
+// number of workers and processes sharing the database never pick the same feed.
+// The claim also pushes next_fetch_at one interval ahead, so a worker that dies
+// mid-fetch does not leave its feed claimed forever.
+func (a *aggregator) claimNextFeed(ctx context.Context) (database.Feed, error) {
+	now := time.Now()
+	return a.s.db.ClaimNextFeedToFetch(ctx,
+		database.ClaimNextFeedToFetchParams{
+			UpdatedAt:   now,
+			NextFetchAt: sql.NullTime{Time: now.Add(a.interval), Valid: true},
+		})
+}
+
+func (a *aggregator) recordSuccess(ctx context.Context, dbFeed database.Feed, scraped scrapeResult) error {
+	now := time.Now()
+	err := a.s.db.RecordFeedSuccess(ctx,
+		database.RecordFeedSuccessParams{
+			ID:        dbFeed.ID,
+			UpdatedAt: now,
+		})
+	if err != nil {
+		return err
+	}
+
+	interval := a.nextFetchInterval(dbFeed.FetchInterval, scraped)
+
+	return a.s.db.UpdateFeedSchedule(ctx,
+		database.UpdateFeedScheduleParams{
+			ID:            dbFeed.ID,
+			UpdatedAt:     now,
+			NextFetchAt:   sql.NullTime{Time: now.Add(interval), Valid: true},
+			FetchInterval: sql.NullInt32{Int32: int32(interval / time.Second), Valid: true},
+		})
+}
+
+// A failing feed is retried after its usual interval doubled for every consecutive
+// failure, and disabled once the failures reach the configured limit.
+func (a *aggregator) recordFailure(ctx context.Context, dbFeed database.Feed, fetchErr error) error {
+	now := time.Now()
+	dbFeed, err := a.s.db.RecordFeedFailure(ctx,
+		database.RecordFeedFailureParams{
+			UpdatedAt:   now,
+			LastError:   sql.NullString{String: fetchErr.Error(), Valid: true},
+			MaxFailures: int32(a.maxFailures),
+			ID:          dbFeed.ID,
+		})
+	if err != nil {
+		return err
+	}
+
+	if dbFeed.DisabledAt.Valid {
+		fmt.Printf("Feed '%s' has been disabled after %d consecutive failures\n", dbFeed.Name, dbFeed.ConsecutiveFailures)
+		return nil
+	}
+
+	backoff := a.feedInterval(dbFeed.FetchInterval)
+	for i := int32(1); i < dbFeed.ConsecutiveFailures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, maxBackoff)
+
+	var statusErr *rss.StatusError
+	if errors.As(fetchErr, &statusErr) {
+		backoff = max(backoff, statusErr.RetryAfter)
+	}
+
+	return a.s.db.UpdateFeedSchedule(ctx,
+		database.UpdateFeedScheduleParams{
+			ID:            dbFeed.ID,
+			UpdatedAt:     now,
+			NextFetchAt:   sql.NullTime{Time: now.Add(backoff), Valid: true},
+			FetchInterval: dbFeed.FetchInterval,
+		})
+}
+
+func (a *aggregator) feedInterval(current sql.NullInt32) time.Duration {
+	if current.Valid && current.Int32 > 0 {
+		return time.Duration(current.Int32) * time.Second
+	}
+	return a.interval
+}
+
+// Feeds that published new posts are fetched twice as often, feeds without news
+// half as often again, within bounds and never sooner than the publisher asks.
+func (a *aggregator) nextFetchInterval(current sql.NullInt32, scraped scrapeResult) time.Duration {
+	interval := a.feedInterval(current)
+
+	if scraped.created > 0 {
+		interval /= 2
+	} else {
+		interval = interval * 3 / 2
+	}
+
+	interval = min(max(interval, min(a.interval, minFetchInterval)), maxFetchInterval)
+
+	// The publisher's minimum goes past the bounds, up to what the schedule can store.
+	return min(max(interval, scraped.minInterval), math.MaxInt32*time.Second)
+}
+
+func (a *aggregator) reportFetchError(host string, err error) {
+	fmt.Println(err)
+
+	var statusErr *rss.StatusError
+	switch {
+	case errors.Is(err, rss.ErrGone), errors.Is(err, rss.ErrNotFound):
+		fmt.Println("The feed URL is no longer valid; consider removing or updating it")
+	case errors.Is(err, rss.ErrNotAFeed):
+		fmt.Println("The feed URL does not point to a feed; check it with a browser")
+	case errors.As(err, &statusErr) && statusErr.RetryAfter > 0:
+		fmt.Printf("Pausing requests to %s for %s\n", host, statusErr.RetryAfter)
+		a.hosts.pause(host, statusErr.RetryAfter)
+	}
+}
+
+func feedHost(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return feedURL
+	}
+	return u.Hostname()
+}
+
+type hostLimiter struct {
+	mu      sync.Mutex
+	perHost int
+	hosts   map[string]*hostSlot
+}
+
+type hostSlot struct {
+	sem    chan struct{}
+	paused time.Time
+}
+
+func newHostLimiter(perHost int) *hostLimiter {
+	return &hostLimiter{
+		perHost: perHost,
+		hosts:   make(map[string]*hostSlot),
+	}
+}
+
+func (h *hostLimiter) slot(host string) *hostSlot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	slot, ok := h.hosts[host]
+	if !ok {
+		slot = &hostSlot{sem: make(chan struct{}, h.perHost)}
+		h.hosts[host] = slot
+	}
+	return slot
+}
+
+// tryAcquire takes a request slot for host without waiting. It fails when every
+// slot is taken, or when requests to host are paused, in which case it also
+// returns how long the pause lasts.
+func (h *hostLimiter) tryAcquire(host string) (time.Duration, bool) {
+	slot := h.slot(host)
+
+	h.mu.Lock()
+	wait := time.Until(slot.paused)
+	h.mu.Unlock()
+
+	if wait > 0 {
+		return wait, false
+	}
+
+	select {
+	case slot.sem <- struct{}{}:
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+func (h *hostLimiter) release(host string) {
+	<-h.slot(host).sem
+}
+
+func (h *hostLimiter) pause(host string, d time.Duration) {
+	slot := h.slot(host)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	slot.paused = time.Now().Add(d)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"internal/database"
+	"internal/rss"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNextFetchInterval(t *testing.T) {
+	seconds := func(d time.Duration) sql.NullInt32 {
+		return sql.NullInt32{Int32: int32(d / time.Second), Valid: true}
+	}
+	const day = 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+		current  sql.NullInt32
+		scraped  scrapeResult
+		want     time.Duration
+	}{
+		{"new feed with news", time.Hour, sql.NullInt32{}, scrapeResult{created: 2}, 30 * time.Minute},
+		{"new feed without news", time.Hour, sql.NullInt32{}, scrapeResult{}, 90 * time.Minute},
+		{"halved", time.Hour, seconds(10 * time.Minute), scrapeResult{created: 1}, 5 * time.Minute},
+		{"grown", time.Hour, seconds(10 * time.Minute), scrapeResult{}, 15 * time.Minute},
+		{"lower bound", time.Hour, seconds(80 * time.Second), scrapeResult{created: 1}, time.Minute},
+		{"lower bound of a short agg interval", 30 * time.Second, seconds(40 * time.Second), scrapeResult{created: 1}, 30 * time.Second},
+		{"upper bound", time.Hour, seconds(20 * time.Hour), scrapeResult{}, day},
+		{"publisher minimum", time.Hour, seconds(2 * time.Hour), scrapeResult{created: 1, minInterval: 3 * time.Hour}, 3 * time.Hour},
+		{"publisher minimum below the interval", time.Hour, seconds(2 * time.Hour), scrapeResult{created: 1, minInterval: 30 * time.Minute}, time.Hour},
+		{"ttl above the upper bound", time.Hour, seconds(time.Hour), scrapeResult{minInterval: 48 * time.Hour}, 48 * time.Hour},
+		{"weekly updates", time.Hour, seconds(day), scrapeResult{created: 3, minInterval: 7 * day}, 7 * day},
+		{"yearly updates", time.Hour, seconds(7 * day), scrapeResult{minInterval: 365 * day}, 365 * day},
+		{"max-age beyond the schedule", time.Hour, seconds(time.Hour), scrapeResult{minInterval: 100 * 365 * day}, math.MaxInt32 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAggregator(nil, tt.interval, 1, 5)
+			if got := a.nextFetchInterval(tt.current, tt.scraped); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordFailureBackoff(t *testing.T) {
+	const day = 24 * time.Hour
+	errFetch := errors.New("connection refused")
+	errRateLimited := &rss.StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 2 * day, Err: rss.ErrRateLimited}
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+		errs     []error
+		// want holds the delay before the next fetch after each failure; zero
+		// means the feed is disabled.
+		want []time.Duration
+	}{
+		{
+			name:     "doubled until disabled",
+			interval: time.Hour,
+			errs:     []error{errFetch, errFetch, errFetch, errFetch, errFetch},
+			want:     []time.Duration{time.Hour, 2 * time.Hour, 4 * time.Hour, 8 * time.Hour, 0},
+		},
+		{
+			name:     "capped",
+			interval: 3 * day,
+			errs:     []error{errFetch, errFetch, errFetch},
+			want:     []time.Duration{3 * day, 6 * day, maxBackoff},
+		},
+		{
+			name:     "retry after",
+			interval: time.Hour,
+			errs:     []error{errRateLimited, errFetch, errRateLimited},
+			want:     []time.Duration{2 * day, 2 * time.Hour, 2 * day},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachStore(t, func(t *testing.T, s *state) {
+				ctx := context.Background()
+				now := time.Now()
+				user, err := s.db.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "alice"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				dbFeed, err := s.db.CreateFeed(ctx, database.CreateFeedParams{
+					ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "Test", Url: "https://example.com/feed", UserID: user.ID,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				dbFeed.FetchInterval = sql.NullInt32{Int32: int32(tt.interval / time.Second), Valid: true}
+				if err := s.db.UpdateFeedSchedule(ctx, database.UpdateFeedScheduleParams{
+					ID: dbFeed.ID, UpdatedAt: now, FetchInterval: dbFeed.FetchInterval,
+				}); err != nil {
+					t.Fatal(err)
+				}
+
+				a := newAggregator(s, time.Minute, 1, 5)
+				for i, fetchErr := range tt.errs {
+					before := time.Now()
+					if err := a.recordFailure(ctx, dbFeed, fetchErr); err != nil {
+						t.Fatal(err)
+					}
+					after := time.Now()
+
+					got, err := s.db.GetFeed(ctx, dbFeed.Url)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if got.ConsecutiveFailures != int32(i+1) {
+						t.Errorf("failure %d: feed has %d failures", i+1, got.ConsecutiveFailures)
+					}
+					if tt.want[i] == 0 {
+						if !got.DisabledAt.Valid {
+							t.Errorf("failure %d: feed is not disabled", i+1)
+						}
+						continue
+					}
+					if got.DisabledAt.Valid {
+						t.Errorf("failure %d: feed is disabled", i+1)
+					}
+					if next := got.NextFetchAt.Time; next.Before(before.Add(tt.want[i])) || next.After(after.Add(tt.want[i])) {
+						t.Errorf("failure %d: next fetch in %s, want %s", i+1, next.Sub(before).Round(time.Second), tt.want[i])
+					}
+					if got.FetchInterval != dbFeed.FetchInterval {
+						t.Errorf("failure %d: interval changed to %v", i+1, got.FetchInterval)
+					}
+				}
+			})
+		})
+	}
+}
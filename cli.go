@@ -1,7 +1,12 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"internal/config"
 	"internal/database"
@@ -10,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -20,30 +26,33 @@ type command struct {
 }
 
 type commands struct {
-	handlers map[string]func(*state, command) error
+	handlers map[string]func(context.Context, *state, command) error
 }
 
-func (c *commands) register(name string, f func(*state, command) error) {
+func (c *commands) register(name string, f func(context.Context, *state, command) error) {
 	c.handlers[name] = f
 }
 
-func (c *commands) run(s *state, cmd command) error {
+func (c *commands) run(ctx context.Context, s *state, cmd command) error {
 	handler, ok := c.handlers[cmd.name]
 	if !ok {
 		return fmt.Errorf("handler for %s not registered", cmd.name)
 	}
-	return handler(s, cmd)
+	return handler(ctx, s, cmd)
 }
 
-func handlerLogin(s *state, cmd command) error {
+func handlerLogin(ctx context.Context, s *state, cmd command) error {
 	if len(cmd.args) != 1 {
 		return fmt.Errorf("login command requires one argument; provided %v", len(cmd.args))
 	}
 
 	user := cmd.args[0]
-	dbUser, err := s.db.GetUser(context.Background(), user)
+	dbUser, err := s.db.GetUser(ctx, user)
 	if err != nil {
-		os.Exit(1)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("user %s not found", user)
+		}
+		return err
 	}
 
 	s.cfg.CurrentUserName = dbUser.Name
@@ -57,13 +66,13 @@ func handlerLogin(s *state, cmd command) error {
 	return nil
 }
 
-func handlerRegister(s *state, cmd command) error {
+func handlerRegister(ctx context.Context, s *state, cmd command) error {
 	if len(cmd.args) != 1 {
 		return fmt.Errorf("register command requires one argument; provided %v", len(cmd.args))
 	}
 
 	user := cmd.args[0]
-	dbUser, err := s.db.CreateUser(context.Background(),
+	dbUser, err := s.db.CreateUser(ctx,
 		database.CreateUserParams{
 			ID:        uuid.New(),
 			CreatedAt: time.Now(),
@@ -71,7 +80,7 @@ func handlerRegister(s *state, cmd command) error {
 			Name:      user,
 		})
 	if err != nil {
-		os.Exit(1)
+		return err
 	}
 
 	fmt.Printf("User has been created: %s\n", dbUser.Name)
@@ -85,14 +94,14 @@ func handlerRegister(s *state, cmd command) error {
 	return nil
 }
 
-func handlerReset(s *state, cmd command) error {
+func handlerReset(ctx context.Context, s *state, cmd command) error {
 	if len(cmd.args) != 0 {
 		return fmt.Errorf("reset command doesn't require arguments; provided %v", len(cmd.args))
 	}
 
-	err := s.db.ResetUsers(context.Background())
+	err := s.db.ResetUsers(ctx)
 	if err != nil {
-		os.Exit(1)
+		return err
 	}
 
 	fmt.Println("User table has been reset")
@@ -100,14 +109,14 @@ func handlerReset(s *state, cmd command) error {
 	return nil
 }
 
-func handlerUsers(s *state, cmd command) error {
+func handlerUsers(ctx context.Context, s *state, cmd command) error {
 	if len(cmd.args) != 0 {
 		return fmt.Errorf("users command doesn't require arguments; provided %v", len(cmd.args))
 	}
 
-	dbUsers, err := s.db.GetUsers(context.Background())
+	dbUsers, err := s.db.GetUsers(ctx)
 	if err != nil {
-		os.Exit(1)
+		return err
 	}
 
 	for _, user := range dbUsers {
@@ -121,33 +130,254 @@ func handlerUsers(s *state, cmd command) error {
 	return nil
 }
 
-func scrapeFeeds(s *state) error {
-	dbFeed, err := s.db.GetNextFeedToFetch(context.Background())
+type scrapeResult struct {
+	notModified bool
+	itemsSeen   int
+	created     int
+	updated     int
+	skipped     int
+	// Shortest refresh interval announced by the publisher, from <ttl>,
+	// the syndication module or Cache-Control.
+	minInterval time.Duration
+	statusCode  int
+	bytes       int64
+	rejected    []rejectedItem
+	// Titles are printed once the transaction storing the posts is committed.
+	createdTitles []string
+	updatedTitles []string
+}
+
+type rejectedItem struct {
+	title string
+	err   error
+}
+
+// Postgres rejects NUL bytes and invalid UTF-8 in text columns, and btree
+// indexes such as the (feed_id, guid) key only accept entries of about 2700 bytes.
+const maxGUIDLength = 2048
+
+func validateItem(item rss.Item) error {
+	key := item.Key()
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"guid", key},
+		{"title", item.Title},
+		{"link", item.Link},
+		{"description", item.Description},
+	}
+	for _, field := range fields {
+		if !utf8.ValidString(field.value) {
+			return fmt.Errorf("%s is not valid UTF-8", field.name)
+		}
+		if strings.ContainsRune(field.value, 0) {
+			return fmt.Errorf("%s contains a NUL character", field.name)
+		}
+	}
+	if len(key) > maxGUIDLength {
+		return fmt.Errorf("guid is longer than %d bytes", maxGUIDLength)
+	}
+	return nil
+}
+
+func scrapeFeeds(ctx context.Context, s *state, dbFeed database.Feed) (scrapeResult, error) {
+	startedAt := time.Now()
+	scraped, err := ingestFeed(ctx, s, dbFeed)
+
+	fetch := database.CreateFeedFetchParams{
+		ID:            uuid.New(),
+		FeedID:        dbFeed.ID,
+		StartedAt:     startedAt,
+		FinishedAt:    time.Now(),
+		HttpStatus:    sql.NullInt32{Int32: int32(scraped.statusCode), Valid: scraped.statusCode != 0},
+		Bytes:         scraped.bytes,
+		ItemsSeen:     int32(scraped.itemsSeen),
+		PostsInserted: int32(scraped.created),
+		ItemsRejected: int32(len(scraped.rejected)),
+	}
 	if err != nil {
-		return err
+		fetch.Error = sql.NullString{String: err.Error(), Valid: true}
 	}
 
-	err = s.db.MarkFeedFetched(context.Background(),
-		database.MarkFeedFetchedParams{
-			ID:        dbFeed.ID,
-			UpdatedAt: time.Now(),
+	// Aborted fetches are recorded too, so the history shows why they are missing posts.
+	if recordErr := s.db.CreateFeedFetch(context.WithoutCancel(ctx), fetch); recordErr != nil {
+		fmt.Printf("error recording fetch of feed '%s': %v\n", dbFeed.Name, recordErr)
+	}
+
+	return scraped, err
+}
+
+func ingestFeed(ctx context.Context, s *state, dbFeed database.Feed) (scrapeResult, error) {
+	var scraped scrapeResult
+
+	result, err := rss.FetchFeed(ctx, dbFeed.Url,
+		rss.CacheValidators{
+			ETag:         dbFeed.Etag.String,
+			LastModified: dbFeed.LastModified.String,
 		})
 	if err != nil {
-		return err
+		var statusErr *rss.StatusError
+		if errors.As(err, &statusErr) {
+			scraped.statusCode = statusErr.StatusCode
+		}
+		return scraped, fmt.Errorf("error fetching feed '%s': %w", dbFeed.Name, err)
 	}
 
-	feed, err := rss.FetchFeed(context.Background(), dbFeed.Url)
-	if err != nil {
-		return err
+	scraped.statusCode = result.StatusCode
+	scraped.bytes = result.Bytes
+
+	scraped.minInterval = result.MaxAge
+	if result.NotModified {
+		scraped.notModified = true
+		fmt.Printf("Feed '%s' has not been modified\n", dbFeed.Name)
+	} else {
+		scraped.minInterval = max(scraped.minInterval, result.Feed.TTL)
+		scraped.itemsSeen = len(result.Feed.Items)
+	}
+
+	if err = storeFeed(ctx, s, dbFeed, result, &scraped); err != nil {
+		// Nothing was stored, whatever the items looked like.
+		scraped.created, scraped.updated, scraped.skipped = 0, 0, 0
+		scraped.createdTitles, scraped.updatedTitles = nil, nil
+		return scraped, err
 	}
 
-	for _, item := range feed.Channel.Item {
-		publishedTime, err := time.Parse(time.RFC1123Z, item.PubDate)
+	for _, title := range scraped.createdTitles {
+		fmt.Printf("Post has been created: %s\n", title)
+	}
+	for _, title := range scraped.updatedTitles {
+		fmt.Printf("Post has been updated: %s\n", title)
+	}
+
+	return scraped, nil
+}
+
+// storeFeed stores the posts of a fetch together with the feed's cache validators
+// and fetch time in a single transaction. They are only stored together, so a
+// failed ingestion is retried in full instead of being answered with 304.
+func storeFeed(ctx context.Context, s *state, dbFeed database.Feed, result *rss.FetchResult, scraped *scrapeResult) error {
+	return s.db.InTx(ctx, func(tx Store) error {
+		if !result.NotModified {
+			err := storeItems(ctx, tx, dbFeed, result.Feed.Items, scraped)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := tx.UpdateFeedCache(ctx,
+			database.UpdateFeedCacheParams{
+				ID:           dbFeed.ID,
+				UpdatedAt:    time.Now(),
+				Etag:         sql.NullString{String: result.Cache.ETag, Valid: result.Cache.ETag != ""},
+				LastModified: sql.NullString{String: result.Cache.LastModified, Valid: result.Cache.LastModified != ""},
+			})
+		if err != nil {
+			return err
+		}
+
+		return tx.MarkFeedFetched(ctx,
+			database.MarkFeedFetchedParams{
+				ID:        dbFeed.ID,
+				UpdatedAt: time.Now(),
+			})
+	})
+}
+
+// postBatchItem is an element of the JSON array of posts stored by CreatePosts.
+type postBatchItem struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Url         string    `json:"url"`
+	Description string    `json:"description"`
+	PublishedAt time.Time `json:"published_at"`
+	Guid        string    `json:"guid"`
+	ContentHash string    `json:"content_hash"`
+}
+
+// storeItems upserts the items of a feed within tx in a single statement. When
+// the batch fails, the items are stored again one by one, each under a savepoint,
+// so that only the items causing the failure are rejected.
+func storeItems(ctx context.Context, tx Store, dbFeed database.Feed, items []rss.Item, scraped *scrapeResult) error {
+	fetchedAt := time.Now()
+
+	// A statement cannot upsert the same post twice, so of the items sharing a
+	// key only the last one is stored, as it would replace the others anyway.
+	batch := make([]rss.Item, 0, len(items))
+	positions := make(map[string]int)
+	for _, item := range items {
+		if err := validateItem(item); err != nil {
+			scraped.reject(dbFeed, item, err)
+			continue
+		}
+		if i, ok := positions[item.Key()]; ok {
+			batch[i] = item
+			scraped.skipped++
+			continue
+		}
+		positions[item.Key()] = len(batch)
+		batch = append(batch, item)
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+
+	var dbPosts []database.CreatePostsRow
+	err := tx.Savepoint(ctx, func() error {
+		posts := make([]postBatchItem, 0, len(batch))
+		for _, item := range batch {
+			posts = append(posts, postBatchItem{
+				ID:          uuid.New(),
+				Title:       item.Title,
+				Url:         item.Link,
+				Description: item.Description,
+				PublishedAt: item.PublishedAt(fetchedAt),
+				Guid:        item.Key(),
+				ContentHash: item.ContentHash(),
+			})
+		}
+		data, err := json.Marshal(posts)
 		if err != nil {
 			return err
 		}
 
-		dbPost, err := s.db.CreatePost(context.Background(),
+		dbPosts, err = tx.CreatePosts(ctx,
+			database.CreatePostsParams{
+				CreatedAt: time.Now(),
+				FeedID:    dbFeed.ID,
+				Items:     data,
+			})
+		return err
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		for _, item := range batch {
+			if err := storeItem(ctx, tx, dbFeed, item, fetchedAt, scraped); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Posts stored already with the same content are not returned.
+	scraped.skipped += len(batch) - len(dbPosts)
+	for _, dbPost := range dbPosts {
+		scraped.stored(dbPost.Title, dbPost.Inserted)
+	}
+
+	return nil
+}
+
+// storeItem upserts a single item within tx under a savepoint, so a rejected item
+// does not abort the transaction for the others.
+func storeItem(ctx context.Context, tx Store, dbFeed database.Feed, item rss.Item, fetchedAt time.Time, scraped *scrapeResult) error {
+	var dbPost database.CreatePostRow
+	skipped := false
+	err := tx.Savepoint(ctx, func() error {
+		var err error
+		dbPost, err = tx.CreatePost(ctx,
 			database.CreatePostParams{
 				ID:          uuid.New(),
 				CreatedAt:   time.Now(),
@@ -155,52 +385,228 @@ func scrapeFeeds(s *state) error {
 				Title:       item.Title,
 				Url:         item.Link,
 				Description: item.Description,
-				PublishedAt: publishedTime,
+				PublishedAt: item.PublishedAt(fetchedAt),
 				FeedID:      dbFeed.ID,
+				Guid:        item.Key(),
+				ContentHash: item.ContentHash(),
 			})
+		if errors.Is(err, sql.ErrNoRows) {
+			// The post is stored already with the same content.
+			skipped = true
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		scraped.reject(dbFeed, item, err)
+		return nil
+	}
+
+	if skipped {
+		scraped.skipped++
+	} else {
+		scraped.stored(dbPost.Title, dbPost.Inserted)
+	}
+	return nil
+}
+
+func (scraped *scrapeResult) stored(title string, inserted bool) {
+	if inserted {
+		scraped.created++
+		scraped.createdTitles = append(scraped.createdTitles, title)
+	} else {
+		scraped.updated++
+		scraped.updatedTitles = append(scraped.updatedTitles, title)
+	}
+}
+
+func (scraped *scrapeResult) reject(dbFeed database.Feed, item rss.Item, err error) {
+	title := item.Title
+	if title == "" {
+		title = item.Key()
+	}
+	scraped.rejected = append(scraped.rejected, rejectedItem{title: title, err: err})
+	fmt.Printf("Post '%s' of feed '%s' has been rejected: %v\n", title, dbFeed.Name, err)
+}
+
+func handlerAggregator(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("agg command requires at least one argument; provided %v", len(cmd.args))
+	}
+
+	timeBetweenRequests, err := time.ParseDuration(cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("invalid duration format: %w", err)
+	}
+
+	flags := flag.NewFlagSet("agg", flag.ContinueOnError)
+	workers := flags.Int("workers", 4, "number of feeds fetched concurrently")
+	perHost := flags.Int("per-host", 1, "number of concurrent requests to a single host")
+	maxFailures := flags.Int("max-failures", defaultMaxFailures, "consecutive failures before a feed is disabled")
+	if err = flags.Parse(cmd.args[1:]); err != nil {
+		return err
+	}
+	if *workers < 1 || *perHost < 1 || *maxFailures < 1 {
+		return fmt.Errorf("agg command requires positive --workers, --per-host and --max-failures values")
+	}
+
+	fmt.Printf("Collecting feeds every %s with %d workers\n", timeBetweenRequests, *workers)
+
+	pool := newAggregator(s, timeBetweenRequests, *perHost, *maxFailures)
+	pool.run(ctx, *workers)
+
+	fmt.Println("Stopped collecting feeds")
+	pool.printSummary()
+
+	return nil
+}
+
+func handlerFetch(ctx context.Context, s *state, cmd command) error {
+	flags := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	all := flags.Bool("all", false, "fetch every enabled feed once")
+	due := flags.Bool("due", false, "fetch the feeds that are due for a refresh")
+	if err := flags.Parse(cmd.args); err != nil {
+		return err
+	}
+
+	modes := flags.NArg()
+	if *all {
+		modes++
+	}
+	if *due {
+		modes++
+	}
+	if modes != 1 || flags.NArg() > 1 {
+		return fmt.Errorf("fetch command requires one of URL, --all or --due")
+	}
+
+	pool := newAggregator(s, defaultFetchInterval, 1, defaultMaxFailures)
+
+	var dbFeeds []database.Feed
+	switch {
+	case *all:
+		var err error
+		dbFeeds, err = s.db.GetEnabledFeeds(ctx)
 		if err != nil {
-			if !strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return err
+		}
+	case *due:
+		for ctx.Err() == nil {
+			dbFeed, err := pool.claimNextFeed(ctx)
+			if errors.Is(err, sql.ErrNoRows) {
+				break
+			}
+			if err != nil {
 				return err
 			}
-			continue
+			fetchOne(ctx, pool, dbFeed)
+		}
+	default:
+		dbFeed, err := s.db.GetFeed(ctx, flags.Arg(0))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("feed %s not found", flags.Arg(0))
+			}
+			return err
 		}
+		dbFeeds = append(dbFeeds, dbFeed)
+	}
 
-		fmt.Printf("Post has been created: %s\n", dbPost)
+	for _, dbFeed := range dbFeeds {
+		if ctx.Err() != nil {
+			break
+		}
+		fetchOne(ctx, pool, dbFeed)
 	}
 
-	return nil
+	pool.printSummary()
+
+	if failures := pool.failures(); failures > 0 {
+		return fmt.Errorf("%d feeds failed", failures)
+	}
+	return ctx.Err()
 }
 
-func handlerAggregator(s *state, cmd command) error {
-	if len(cmd.args) != 1 {
-		return fmt.Errorf("agg command requires one argument; provided %v", len(cmd.args))
+func fetchOne(ctx context.Context, pool *aggregator, dbFeed database.Feed) {
+	scraped, err := pool.fetchFeed(ctx, dbFeed)
+	if err != nil {
+		return
+	}
+	if scraped.notModified {
+		return
+	}
+	fmt.Printf("Feed '%s': %d new posts, %d updated, %d skipped, %d rejected\n",
+		dbFeed.Name, scraped.created, scraped.updated, scraped.skipped, len(scraped.rejected))
+	for _, rejected := range scraped.rejected {
+		fmt.Printf("  Rejected '%s': %v\n", rejected.title, rejected.err)
 	}
+}
 
-	timeBetweenRequests, err := time.ParseDuration(cmd.args[0])
+func handlerFeedHealth(ctx context.Context, s *state, cmd command) error {
+	flags := flag.NewFlagSet("feed-health", flag.ContinueOnError)
+	days := flags.Int("days", 7, "number of days of fetch history to summarize")
+	if err := flags.Parse(cmd.args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 || *days < 1 {
+		return fmt.Errorf("feed-health command accepts only a positive --days option")
+	}
+
+	now := time.Now()
+	dbHealth, err := s.db.GetFeedHealth(ctx, now.AddDate(0, 0, -*days))
 	if err != nil {
-		fmt.Printf("invalid duration format: %v\n", err)
-		os.Exit(1)
+		return err
 	}
 
-	fmt.Printf("Collecting feeds every %s\n", timeBetweenRequests)
+	fmt.Printf("Feed health over the last %d days\n", *days)
+	for _, health := range dbHealth {
+		status := ""
+		if health.DisabledAt.Valid {
+			status = " (disabled)"
+		}
+		fmt.Printf("'%s' at %s%s\n", health.Name, health.Url, status)
+
+		if health.Fetches == 0 {
+			fmt.Println("  No fetches")
+		} else {
+			fmt.Printf("  Success rate: %.0f%% of %d fetches\n", 100*float64(health.Successes)/float64(health.Fetches), health.Fetches)
+			fmt.Printf("  Average latency: %s\n", time.Duration(health.AvgLatencySeconds*float64(time.Second)).Round(time.Millisecond))
+			fmt.Printf("  Items seen: %d, posts inserted: %d\n", health.ItemsSeen, health.PostsInserted)
+		}
 
-	ticker := time.NewTicker(timeBetweenRequests)
-	for ; ; <-ticker.C {
-		err = scrapeFeeds(s)
-		if err != nil {
-			fmt.Println(err)
+		if health.LastSuccessAt.Valid {
+			fmt.Printf("  Last success: %s ago\n", now.Sub(health.LastSuccessAt.Time).Round(time.Minute))
+		}
+		if health.LatestPostAt.Valid {
+			fmt.Printf("  Latest post: %s ago\n", now.Sub(health.LatestPostAt.Time).Round(time.Minute))
 		}
 	}
+
+	return nil
 }
 
-func handlerAddFeed(s *state, cmd command, dbUser database.User) error {
+func handlerAddFeed(ctx context.Context, s *state, cmd command, dbUser database.User) error {
 	if len(cmd.args) != 2 {
 		return fmt.Errorf("addfeed command requires two arguments; provided %v", len(cmd.args))
 	}
 
 	name := cmd.args[0]
-	url := cmd.args[1]
-	dbFeed, err := s.db.CreateFeed(context.Background(),
+
+	links, err := rss.DiscoverFeeds(ctx, cmd.args[1])
+	if err != nil {
+		return fmt.Errorf("error discovering feeds at %s: %w", cmd.args[1], err)
+	}
+
+	link, err := chooseFeedLink(links)
+	if err != nil {
+		return err
+	}
+
+	url := link.URL
+	dbFeed, err := s.db.CreateFeed(ctx,
 		database.CreateFeedParams{
 			ID:        uuid.New(),
 			CreatedAt: time.Now(),
@@ -210,12 +616,12 @@ func handlerAddFeed(s *state, cmd command, dbUser database.User) error {
 			UserID:    dbUser.ID,
 		})
 	if err != nil {
-		os.Exit(1)
+		return err
 	}
 
 	fmt.Printf("Feed has been added: %s\n", dbFeed.Name)
 
-	dbFollow, err := s.db.CreateFeedFollow(context.Background(),
+	dbFollow, err := s.db.CreateFeedFollow(ctx,
 		database.CreateFeedFollowParams{
 			ID:        uuid.New(),
 			CreatedAt: time.Now(),
@@ -224,7 +630,7 @@ func handlerAddFeed(s *state, cmd command, dbUser database.User) error {
 			FeedID:    dbFeed.ID,
 		})
 	if err != nil {
-		os.Exit(1)
+		return err
 	}
 
 	fmt.Printf("User %s is now following feed '%s'\n", dbFollow.UserName, dbFollow.FeedName)
@@ -232,14 +638,51 @@ func handlerAddFeed(s *state, cmd command, dbUser database.User) error {
 	return nil
 }
 
-func handlerFeeds(s *state, cmd command) error {
-	if len(cmd.args) != 0 {
-		return fmt.Errorf("feeds command doesn't require arguments; provided %v", len(cmd.args))
+func chooseFeedLink(links []rss.FeedLink) (rss.FeedLink, error) {
+	if len(links) == 1 {
+		return links[0], nil
 	}
 
-	dbFeeds, err := s.db.GetFeeds(context.Background())
+	fmt.Println("Several feeds were found:")
+	for i, link := range links {
+		title := link.Title
+		if title == "" {
+			title = "(untitled)"
+		}
+		fmt.Printf("%d) %s <%s>\n", i+1, title, link.URL)
+	}
+	fmt.Printf("Choose a feed [1-%d]: ", len(links))
+
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		return rss.FeedLink{}, fmt.Errorf("no feed chosen")
+	}
+
+	choice, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+	if err != nil || choice < 1 || choice > len(links) {
+		return rss.FeedLink{}, fmt.Errorf("invalid choice: %s", scanner.Text())
+	}
+
+	return links[choice-1], nil
+}
+
+func handlerFeeds(ctx context.Context, s *state, cmd command) error {
+	flags := flag.NewFlagSet("feeds", flag.ContinueOnError)
+	broken := flags.Bool("broken", false, "list only failing or disabled feeds")
+	if err := flags.Parse(cmd.args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("feeds command doesn't require arguments; provided %v", flags.NArg())
+	}
+
+	if *broken {
+		return listBrokenFeeds(ctx, s)
+	}
+
+	dbFeeds, err := s.db.GetFeeds(ctx)
 	if err != nil {
-		os.Exit(1)
+		return err
 	}
 
 	for _, feed := range dbFeeds {
@@ -249,18 +692,67 @@ func handlerFeeds(s *state, cmd command) error {
 	return nil
 }
 
-func handlerAddFollow(s *state, cmd command, dbUser database.User) error {
+func listBrokenFeeds(ctx context.Context, s *state) error {
+	dbFeeds, err := s.db.GetBrokenFeeds(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, feed := range dbFeeds {
+		status := fmt.Sprintf("failing (%d consecutive failures)", feed.ConsecutiveFailures)
+		if feed.DisabledAt.Valid {
+			status = fmt.Sprintf("disabled since %s (%d consecutive failures)", feed.DisabledAt.Time, feed.ConsecutiveFailures)
+		}
+		fmt.Printf("'%s' at %s: %s\n", feed.Name, feed.Url, status)
+		if feed.LastSuccessAt.Valid {
+			fmt.Printf("Last success at %s\n", feed.LastSuccessAt.Time)
+		} else {
+			fmt.Println("Never fetched successfully")
+		}
+		fmt.Printf("Last error: %s\n", feed.LastError.String)
+	}
+
+	return nil
+}
+
+func handlerFeed(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.args) != 2 || cmd.args[0] != "enable" {
+		return fmt.Errorf("feed command requires the subcommand 'enable' and a feed URL")
+	}
+
+	url := cmd.args[1]
+	dbFeed, err := s.db.EnableFeed(ctx,
+		database.EnableFeedParams{
+			Url:       url,
+			UpdatedAt: time.Now(),
+		})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("feed %s not found", url)
+		}
+		return err
+	}
+
+	fmt.Printf("Feed '%s' has been enabled\n", dbFeed.Name)
+
+	return nil
+}
+
+func handlerAddFollow(ctx context.Context, s *state, cmd command, dbUser database.User) error {
 	if len(cmd.args) != 1 {
 		return fmt.Errorf("follow command requires one argument; provided %v", len(cmd.args))
 	}
 
 	url := cmd.args[0]
-	dbFeed, err := s.db.GetFeed(context.Background(), url)
+	dbFeed, err := s.db.GetFeed(ctx, url)
 	if err != nil {
-		os.Exit(1)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("feed %s not found", url)
+		}
+		return err
 	}
 
-	dbFollow, err := s.db.CreateFeedFollow(context.Background(),
+	dbFollow, err := s.db.CreateFeedFollow(ctx,
 		database.CreateFeedFollowParams{
 			ID:        uuid.New(),
 			CreatedAt: time.Now(),
@@ -269,7 +761,7 @@ func handlerAddFollow(s *state, cmd command, dbUser database.User) error {
 			FeedID:    dbFeed.ID,
 		})
 	if err != nil {
-		os.Exit(1)
+		return err
 	}
 
 	fmt.Printf("User %s is now following feed '%s'\n", dbFollow.UserName, dbFollow.FeedName)
@@ -277,36 +769,36 @@ func handlerAddFollow(s *state, cmd command, dbUser database.User) error {
 	return nil
 }
 
-func handlerFollowing(s *state, cmd command, dbUser database.User) error {
+func handlerFollowing(ctx context.Context, s *state, cmd command, dbUser database.User) error {
 	if len(cmd.args) != 0 {
 		return fmt.Errorf("following command doesn't require arguments; provided %v", len(cmd.args))
 	}
 
-	dbFollows, err := s.db.GetFeedFollowsForUser(context.Background(), dbUser.ID)
+	dbFollows, err := s.db.GetFeedFollowsForUser(ctx, dbUser.ID)
 	if err != nil {
-		os.Exit(1)
+		return err
 	}
 
 	for _, dbFollow := range dbFollows {
-		fmt.Printf("Following '%s'\n", dbFollow.FeedName)
+		fmt.Printf("Following '%s' (%d unread)\n", dbFollow.FeedName, dbFollow.UnreadPosts)
 	}
 
 	return nil
 }
 
-func handlerUnfollow(s *state, cmd command, dbUser database.User) error {
+func handlerUnfollow(ctx context.Context, s *state, cmd command, dbUser database.User) error {
 	if len(cmd.args) != 1 {
 		return fmt.Errorf("unfollow command requires one argument; provided %v", len(cmd.args))
 	}
 
 	url := cmd.args[0]
-	err := s.db.DeleteFollow(context.Background(),
+	err := s.db.DeleteFollow(ctx,
 		database.DeleteFollowParams{
 			UserID: dbUser.ID,
 			Url:    url,
 		})
 	if err != nil {
-		os.Exit(1)
+		return err
 	}
 
 	fmt.Printf("Feed %s was unfollowed\n", url)
@@ -314,44 +806,200 @@ func handlerUnfollow(s *state, cmd command, dbUser database.User) error {
 	return nil
 }
 
-func handlerBrowse(s *state, cmd command, dbUser database.User) error {
-	if len(cmd.args) > 1 {
-		return fmt.Errorf("browse command requires at most one argument; provided %v", len(cmd.args))
+func handlerBrowse(ctx context.Context, s *state, cmd command, dbUser database.User) error {
+	flags := flag.NewFlagSet("browse", flag.ContinueOnError)
+	unread := flags.Bool("unread", false, "list only the posts not marked as read")
+	if err := flags.Parse(cmd.args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return fmt.Errorf("browse command requires at most one argument; provided %v", flags.NArg())
 	}
 
 	limit := 2
-	if len(cmd.args) == 1 {
+	if flags.NArg() == 1 {
 		var err error
-		limit, err = strconv.Atoi(cmd.args[0])
+		limit, err = strconv.Atoi(flags.Arg(0))
 		if err != nil {
-			os.Exit(1)
+			return fmt.Errorf("invalid limit %s: %w", flags.Arg(0), err)
 		}
 	}
 
-	dbPosts, err := s.db.GetPosts(context.Background(),
-		database.GetPostsParams{
-			UserID: dbUser.ID,
-			Limit:  int32(limit),
-		})
+	var dbPosts []database.Post
+	var err error
+	if *unread {
+		dbPosts, err = s.db.GetUnreadPosts(ctx,
+			database.GetUnreadPostsParams{
+				UserID: dbUser.ID,
+				Limit:  int32(limit),
+			})
+	} else {
+		dbPosts, err = s.db.GetPosts(ctx,
+			database.GetPostsParams{
+				UserID: dbUser.ID,
+				Limit:  int32(limit),
+			})
+	}
 	if err != nil {
-		os.Exit(1)
+		return err
 	}
 
 	for _, dbPost := range dbPosts {
+		fmt.Printf("ID %s\n", dbPost.ID)
 		fmt.Printf("Published at %s\n", dbPost.PublishedAt)
+		if dbPost.RevisedAt.Valid {
+			fmt.Printf("Updated at %s\n", dbPost.RevisedAt.Time)
+		}
+		fmt.Printf("Post '%s' <%s>\n", dbPost.Title, dbPost.Url)
+		fmt.Printf("Description: %s\n", dbPost.Description)
+	}
+
+	return nil
+}
+
+func handlerRead(ctx context.Context, s *state, cmd command, dbUser database.User) error {
+	flags := flag.NewFlagSet("read", flag.ContinueOnError)
+	all := flags.Bool("all", false, "mark every post of the followed feeds as read")
+	feedURL := flags.String("feed", "", "with --all, mark only the posts of the feed at this URL")
+	if err := flags.Parse(cmd.args); err != nil {
+		return err
+	}
+
+	if !*all {
+		if flags.NArg() != 1 || *feedURL != "" {
+			return fmt.Errorf("read command requires a post ID, or --all with an optional --feed URL")
+		}
+		postID, err := uuid.Parse(flags.Arg(0))
+		if err != nil {
+			return fmt.Errorf("invalid post ID %s", flags.Arg(0))
+		}
+
+		marked, err := s.db.MarkPostRead(ctx,
+			database.MarkPostReadParams{
+				ReadAt: time.Now(),
+				UserID: dbUser.ID,
+				PostID: postID,
+			})
+		if err != nil {
+			return err
+		}
+		if marked == 0 {
+			return fmt.Errorf("post %s not found in the followed feeds", postID)
+		}
+
+		fmt.Printf("Post %s has been marked as read\n", postID)
+		return nil
+	}
+
+	if flags.NArg() != 0 {
+		return fmt.Errorf("read command does not take a post ID with --all")
+	}
+
+	params := database.MarkAllPostsReadParams{
+		ReadAt: time.Now(),
+		UserID: dbUser.ID,
+	}
+	if *feedURL != "" {
+		if _, err := s.db.GetFeed(ctx, *feedURL); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("feed %s not found", *feedURL)
+			}
+			return err
+		}
+		params.FeedUrl = sql.NullString{String: *feedURL, Valid: true}
+	}
+
+	marked, err := s.db.MarkAllPostsRead(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d posts have been marked as read\n", marked)
+
+	return nil
+}
+
+const searchLimit = 20
+
+func handlerSearch(ctx context.Context, s *state, cmd command, dbUser database.User) error {
+	if len(cmd.args) < 1 || strings.TrimSpace(cmd.args[0]) == "" {
+		return fmt.Errorf("search command requires a query")
+	}
+
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	feedURL := flags.String("feed", "", "search only the posts of the feed at this URL")
+	since := flags.String("since", "", "search only the posts published since this date (YYYY-MM-DD)")
+	if err := flags.Parse(cmd.args[1:]); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("search command requires a single query; quote queries with several words")
+	}
+
+	params := database.SearchPostsParams{
+		Query:  cmd.args[0],
+		Now:    time.Now(),
+		UserID: dbUser.ID,
+		Limit:  searchLimit,
+	}
+	if *feedURL != "" {
+		if _, err := s.db.GetFeed(ctx, *feedURL); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("feed %s not found", *feedURL)
+			}
+			return err
+		}
+		params.FeedUrl = sql.NullString{String: *feedURL, Valid: true}
+	}
+	if *since != "" {
+		sinceTime, err := parseSince(*since)
+		if err != nil {
+			return err
+		}
+		params.Since = sql.NullTime{Time: sinceTime, Valid: true}
+	}
+
+	dbPosts, err := s.db.SearchPosts(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	if len(dbPosts) == 0 {
+		fmt.Println("No posts found")
+		return nil
+	}
+
+	for _, dbPost := range dbPosts {
+		fmt.Printf("ID %s\n", dbPost.ID)
 		fmt.Printf("Post '%s' <%s>\n", dbPost.Title, dbPost.Url)
+		fmt.Printf("Feed '%s', published at %s\n", dbPost.FeedName, dbPost.PublishedAt)
 		fmt.Printf("Description: %s\n", dbPost.Description)
 	}
 
 	return nil
 }
 
-func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
-	return func(s *state, cmd command) error {
-		dbUser, err := s.db.GetUser(context.Background(), s.cfg.CurrentUserName)
+// parseSince reads a date, or a full RFC 3339 time, in the local time zone.
+func parseSince(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %s; use YYYY-MM-DD", value)
+	}
+	return t, nil
+}
+
+func middlewareLoggedIn(handler func(ctx context.Context, s *state, cmd command, user database.User) error) func(context.Context, *state, command) error {
+	return func(ctx context.Context, s *state, cmd command) error {
+		dbUser, err := s.db.GetUser(ctx, s.cfg.CurrentUserName)
 		if err != nil {
-			os.Exit(1)
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("user %s not found; log in or register first", s.cfg.CurrentUserName)
+			}
+			return err
 		}
-		return handler(s, cmd, dbUser)
+		return handler(ctx, s, cmd, dbUser)
 	}
 }
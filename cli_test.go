@@ -0,0 +1,624 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"internal/config"
+	"internal/database"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newTestState(t *testing.T, db Store) *state {
+	t.Helper()
+
+	// Logging in updates the configuration file in the home directory.
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, ".gatorconfig.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return &state{
+		db:  db,
+		cfg: &config.Config{},
+	}
+}
+
+// forEachStore runs test against the in-memory store and against a SQLite
+// database, so the handlers are checked with the queries they run in use.
+func forEachStore(t *testing.T, test func(t *testing.T, s *state)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, newTestState(t, newMemStore()))
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		conn := newSQLiteConn(t)
+		migrateSQLite(t, conn, 0)
+		s := newTestState(t, newSQLStore(conn, dialectSQLite))
+		s.conn, s.dialect = conn, dialectSQLite
+		test(t, s)
+	})
+}
+
+func runCommand(t *testing.T, s *state, handler func(context.Context, *state, command) error, args ...string) error {
+	t.Helper()
+	return handler(context.Background(), s, command{name: "test", args: args})
+}
+
+func mustRun(t *testing.T, s *state, handler func(context.Context, *state, command) error, args ...string) {
+	t.Helper()
+	if err := runCommand(t, s, handler, args...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+type testItem struct {
+	guid  string
+	title string
+}
+
+// testFeed serves an RSS feed whose items and status can change between fetches.
+// When etag is set, requests sending it back are answered with 304.
+type testFeed struct {
+	mu          sync.Mutex
+	status      int
+	items       []testItem
+	etag        string
+	ifNoneMatch string
+}
+
+func newTestFeed(t *testing.T, items ...testItem) (*testFeed, string) {
+	t.Helper()
+
+	feed := &testFeed{status: http.StatusOK, items: items}
+	server := httptest.NewServer(feed)
+	t.Cleanup(server.Close)
+
+	return feed, server.URL + "/feed.xml"
+}
+
+func (f *testFeed) set(status int, items ...testItem) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.status = status
+	f.items = items
+}
+
+func (f *testFeed) setETag(etag string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.etag = etag
+}
+
+// lastIfNoneMatch returns the If-None-Match header of the latest request.
+func (f *testFeed) lastIfNoneMatch() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.ifNoneMatch
+}
+
+func (f *testFeed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.ifNoneMatch = r.Header.Get("If-None-Match")
+	if f.etag != "" {
+		w.Header().Set("ETag", f.etag)
+		if f.ifNoneMatch == f.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	if f.status != http.StatusOK {
+		if f.status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "3600")
+		}
+		w.WriteHeader(f.status)
+		return
+	}
+
+	var body strings.Builder
+	body.WriteString(`<?xml version="1.0"?><rss version="2.0"><channel><title>Test feed</title>`)
+	for _, item := range f.items {
+		fmt.Fprintf(&body, "<item><guid>%s</guid><title>%s</title><link>https://example.com/%s</link></item>",
+			item.guid, item.title, item.guid)
+	}
+	body.WriteString(`</channel></rss>`)
+
+	w.Header().Set("Content-Type", "application/rss+xml")
+	w.Write([]byte(body.String()))
+}
+
+func currentUser(t *testing.T, s *state) database.User {
+	t.Helper()
+
+	dbUser, err := s.db.GetUser(context.Background(), s.cfg.CurrentUserName)
+	if err != nil {
+		t.Fatalf("error reading current user %q: %v", s.cfg.CurrentUserName, err)
+	}
+	return dbUser
+}
+
+func userPosts(t *testing.T, s *state) []database.Post {
+	t.Helper()
+
+	dbPosts, err := s.db.GetPosts(context.Background(),
+		database.GetPostsParams{
+			UserID: currentUser(t, s).ID,
+			Limit:  100,
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dbPosts
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *state) {
+		mustRun(t, s, handlerRegister, "alice")
+		if s.cfg.CurrentUserName != "alice" {
+			t.Errorf("current user is %q after registering alice", s.cfg.CurrentUserName)
+		}
+
+		if err := runCommand(t, s, handlerRegister, "alice"); err == nil {
+			t.Error("registering alice twice succeeded")
+		}
+
+		mustRun(t, s, handlerRegister, "bob")
+		mustRun(t, s, handlerLogin, "alice")
+		if s.cfg.CurrentUserName != "alice" {
+			t.Errorf("current user is %q after logging in as alice", s.cfg.CurrentUserName)
+		}
+
+		cfg, err := config.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.CurrentUserName != "alice" {
+			t.Errorf("configuration file has current user %q after logging in as alice", cfg.CurrentUserName)
+		}
+
+		if err := runCommand(t, s, handlerLogin, "carol"); err == nil {
+			t.Error("logging in as an unknown user succeeded")
+		}
+		if err := runCommand(t, s, handlerLogin); err == nil {
+			t.Error("login without arguments succeeded")
+		}
+	})
+}
+
+func TestReset(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *state) {
+		_, feedURL := newTestFeed(t, testItem{"1", "One"})
+
+		mustRun(t, s, handlerRegister, "alice")
+		mustRun(t, s, middlewareLoggedIn(handlerAddFeed), "Test", feedURL)
+		mustRun(t, s, handlerReset)
+
+		dbUsers, err := s.db.GetUsers(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dbUsers) != 0 {
+			t.Errorf("%d users left after reset", len(dbUsers))
+		}
+
+		if _, err := s.db.GetFeed(context.Background(), feedURL); err == nil {
+			t.Error("feed left after reset")
+		}
+	})
+}
+
+func TestLoggedInCommandsRequireUser(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *state) {
+		if err := runCommand(t, s, middlewareLoggedIn(handlerFollowing)); err == nil {
+			t.Error("following succeeded without a registered user")
+		}
+	})
+}
+
+func TestFollowAndUnfollow(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *state) {
+		_, feedURL := newTestFeed(t, testItem{"1", "One"})
+
+		mustRun(t, s, handlerRegister, "alice")
+		mustRun(t, s, middlewareLoggedIn(handlerAddFeed), "Test", feedURL)
+		mustRun(t, s, handlerRegister, "bob")
+
+		if err := runCommand(t, s, middlewareLoggedIn(handlerAddFollow), "https://example.com/missing.xml"); err == nil {
+			t.Error("following an unknown feed succeeded")
+		}
+
+		mustRun(t, s, middlewareLoggedIn(handlerAddFollow), feedURL)
+		if err := runCommand(t, s, middlewareLoggedIn(handlerAddFollow), feedURL); err == nil {
+			t.Error("following a feed twice succeeded")
+		}
+
+		dbFollows, err := s.db.GetFeedFollowsForUser(context.Background(), currentUser(t, s).ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dbFollows) != 1 || dbFollows[0].FeedName != "Test" {
+			t.Fatalf("bob follows %+v, want only feed 'Test'", dbFollows)
+		}
+		mustRun(t, s, middlewareLoggedIn(handlerFollowing))
+
+		mustRun(t, s, middlewareLoggedIn(handlerUnfollow), feedURL)
+
+		dbFollows, err = s.db.GetFeedFollowsForUser(context.Background(), currentUser(t, s).ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dbFollows) != 0 {
+			t.Errorf("bob follows %d feeds after unfollowing, want 0", len(dbFollows))
+		}
+	})
+}
+
+func TestFetchStoresPosts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *state) {
+		feed, feedURL := newTestFeed(t, testItem{"1", "One"}, testItem{"2", "Two"})
+
+		mustRun(t, s, handlerRegister, "alice")
+		mustRun(t, s, middlewareLoggedIn(handlerAddFeed), "Test", feedURL)
+		mustRun(t, s, handlerFetch, feedURL)
+
+		if dbPosts := userPosts(t, s); len(dbPosts) != 2 {
+			t.Fatalf("%d posts after the first fetch, want 2", len(dbPosts))
+		}
+
+		feed.set(http.StatusOK, testItem{"1", "One"}, testItem{"2", "Two, revised"}, testItem{"3", "Three"})
+		mustRun(t, s, handlerFetch, "--all")
+
+		dbPosts := userPosts(t, s)
+		if len(dbPosts) != 3 {
+			t.Fatalf("%d posts after the second fetch, want 3", len(dbPosts))
+		}
+		for _, dbPost := range dbPosts {
+			if dbPost.Guid == "2" && (dbPost.Title != "Two, revised" || !dbPost.RevisedAt.Valid) {
+				t.Errorf("post 2 was not revised: %+v", dbPost)
+			}
+		}
+
+		mustRun(t, s, middlewareLoggedIn(handlerBrowse), "10")
+		if err := runCommand(t, s, middlewareLoggedIn(handlerBrowse), "ten"); err == nil {
+			t.Error("browse with an invalid limit succeeded")
+		}
+	})
+}
+
+func TestFetchNotModified(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *state) {
+		feed, feedURL := newTestFeed(t, testItem{"1", "One"})
+		feed.setETag(`"v1"`)
+
+		mustRun(t, s, handlerRegister, "alice")
+		mustRun(t, s, middlewareLoggedIn(handlerAddFeed), "Test", feedURL)
+		mustRun(t, s, handlerFetch, feedURL)
+
+		dbFeed, err := s.db.GetFeed(context.Background(), feedURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dbFeed.Etag.String != `"v1"` {
+			t.Fatalf("stored ETag %q after the first fetch, want %q", dbFeed.Etag.String, `"v1"`)
+		}
+
+		// The server answers 304 even though its items changed, so nothing is stored.
+		feed.set(http.StatusOK, testItem{"1", "One"}, testItem{"2", "Two"})
+		mustRun(t, s, handlerFetch, feedURL)
+
+		if got := feed.lastIfNoneMatch(); got != `"v1"` {
+			t.Errorf("second fetch sent If-None-Match %q, want %q", got, `"v1"`)
+		}
+		if dbPosts := userPosts(t, s); len(dbPosts) != 1 {
+			t.Errorf("%d posts after a 304 response, want 1", len(dbPosts))
+		}
+		dbFeed, err = s.db.GetFeed(context.Background(), feedURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dbFeed.Etag.String != `"v1"` || !dbFeed.LastFetchedAt.Valid {
+			t.Errorf("feed after a 304 response = %+v", dbFeed)
+		}
+	})
+}
+
+func TestFetchRejectsInvalidItems(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *state) {
+		_, feedURL := newTestFeed(t,
+			testItem{"1", "One"},
+			testItem{strings.Repeat("x", maxGUIDLength+1), "Too long"},
+		)
+
+		mustRun(t, s, handlerRegister, "alice")
+		mustRun(t, s, middlewareLoggedIn(handlerAddFeed), "Test", feedURL)
+		mustRun(t, s, handlerFetch, feedURL)
+
+		dbPosts := userPosts(t, s)
+		if len(dbPosts) != 1 || dbPosts[0].Title != "One" {
+			t.Errorf("posts %+v, want only 'One'", dbPosts)
+		}
+	})
+}
+
+func TestFetchFailuresAndEnable(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *state) {
+		feed, feedURL := newTestFeed(t, testItem{"1", "One"})
+
+		mustRun(t, s, handlerRegister, "alice")
+		mustRun(t, s, middlewareLoggedIn(handlerAddFeed), "Test", feedURL)
+
+		feed.set(http.StatusInternalServerError)
+		if err := runCommand(t, s, handlerFetch, feedURL); err == nil {
+			t.Fatal("fetching a failing feed succeeded")
+		}
+
+		dbFeed, err := s.db.GetFeed(context.Background(), feedURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dbFeed.ConsecutiveFailures != 1 || !dbFeed.LastError.Valid {
+			t.Errorf("feed after a failed fetch has %d failures and error %q", dbFeed.ConsecutiveFailures, dbFeed.LastError.String)
+		}
+		if len(userPosts(t, s)) != 0 {
+			t.Error("a failed fetch stored posts")
+		}
+
+		mustRun(t, s, handlerFeeds, "--broken")
+		mustRun(t, s, handlerFeedHealth)
+
+		mustRun(t, s, handlerFeed, "enable", feedURL)
+		if err := runCommand(t, s, handlerFeed, "enable", "https://example.com/missing.xml"); err == nil {
+			t.Error("enabling an unknown feed succeeded")
+		}
+
+		feed.set(http.StatusOK, testItem{"1", "One"})
+		mustRun(t, s, handlerFetch, "--due")
+
+		dbFeed, err = s.db.GetFeed(context.Background(), feedURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dbFeed.ConsecutiveFailures != 0 || !dbFeed.LastSuccessAt.Valid {
+			t.Errorf("feed after a successful fetch has %d failures and last success %v", dbFeed.ConsecutiveFailures, dbFeed.LastSuccessAt)
+		}
+		if len(userPosts(t, s)) != 1 {
+			t.Error("the due fetch did not store the post")
+		}
+	})
+}
+
+func TestAggregatorDefersPausedHost(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *state) {
+		limited, limitedURL := newTestFeed(t, testItem{"1", "One"})
+		// Both test servers listen on 127.0.0.1, so they share a host.
+		_, otherURL := newTestFeed(t, testItem{"2", "Two"})
+
+		mustRun(t, s, handlerRegister, "alice")
+		mustRun(t, s, middlewareLoggedIn(handlerAddFeed), "Limited", limitedURL)
+		mustRun(t, s, middlewareLoggedIn(handlerAddFeed), "Other", otherURL)
+
+		ctx := context.Background()
+		pool := newAggregator(s, time.Minute, 1, defaultMaxFailures)
+
+		limited.set(http.StatusTooManyRequests)
+		limitedFeed, err := s.db.GetFeed(ctx, limitedURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := pool.fetchFeed(ctx, limitedFeed); err == nil {
+			t.Fatal("fetching a rate-limited feed succeeded")
+		}
+
+		otherFeed, err := s.db.GetFeed(ctx, otherURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := time.Now()
+		if _, err := pool.fetchFeed(ctx, otherFeed); !errors.Is(err, errHostUnavailable) {
+			t.Fatalf("got %v for a feed of a paused host, want %v", err, errHostUnavailable)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("deferring the feed took %s", elapsed)
+		}
+
+		otherFeed, err = s.db.GetFeed(ctx, otherURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !otherFeed.NextFetchAt.Valid || otherFeed.NextFetchAt.Time.Before(start.Add(59*time.Minute)) {
+			t.Errorf("deferred feed is next fetched at %v, want after the pause", otherFeed.NextFetchAt)
+		}
+		if otherFeed.ConsecutiveFailures != 0 {
+			t.Errorf("deferred feed has %d failures", otherFeed.ConsecutiveFailures)
+		}
+
+		// A busy host defers its feeds only briefly.
+		busy := newAggregator(s, time.Minute, 1, defaultMaxFailures)
+		if _, ok := busy.hosts.tryAcquire(feedHost(otherURL)); !ok {
+			t.Fatal("could not take the only slot of an idle host")
+		}
+		start = time.Now()
+		if _, err := busy.fetchFeed(ctx, otherFeed); !errors.Is(err, errHostUnavailable) {
+			t.Fatalf("got %v for a feed of a busy host, want %v", err, errHostUnavailable)
+		}
+		otherFeed, err = s.db.GetFeed(ctx, otherURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next := otherFeed.NextFetchAt.Time; next.After(start.Add(time.Minute)) {
+			t.Errorf("feed of a busy host is next fetched at %v, want within %s", next, busyHostDelay)
+		}
+	})
+}
+
+func TestFetchArguments(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *state) {
+		for _, args := range [][]string{{}, {"--all", "--due"}, {"a", "b"}} {
+			if err := runCommand(t, s, handlerFetch, args...); err == nil {
+				t.Errorf("fetch %v succeeded", args)
+			}
+		}
+		if err := runCommand(t, s, handlerFetch, "https://example.com/missing.xml"); err == nil {
+			t.Error("fetching an unknown feed succeeded")
+		}
+	})
+}
+
+func TestSearch(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *state) {
+		_, feedURL := newTestFeed(t, testItem{"1", "Go generics"}, testItem{"2", "Rust traits"})
+		_, otherURL := newTestFeed(t, testItem{"3", "More generics"})
+
+		mustRun(t, s, handlerRegister, "alice")
+		mustRun(t, s, middlewareLoggedIn(handlerAddFeed), "Test", feedURL)
+		mustRun(t, s, middlewareLoggedIn(handlerAddFeed), "Other", otherURL)
+		mustRun(t, s, handlerFetch, "--all")
+
+		search := func(args ...string) []string {
+			t.Helper()
+			dbUser := currentUser(t, s)
+			params := database.SearchPostsParams{
+				Query:  args[0],
+				Now:    time.Now(),
+				UserID: dbUser.ID,
+				Limit:  searchLimit,
+			}
+			if len(args) > 1 {
+				params.FeedUrl = sql.NullString{String: args[1], Valid: true}
+			}
+			dbPosts, err := s.db.SearchPosts(context.Background(), params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var titles []string
+			for _, dbPost := range dbPosts {
+				titles = append(titles, dbPost.Title)
+			}
+			slices.Sort(titles)
+			return titles
+		}
+
+		if titles := search("generics"); !slices.Equal(titles, []string{"Go generics", "More generics"}) {
+			t.Errorf("search for generics found %v", titles)
+		}
+		if titles := search("generics", feedURL); !slices.Equal(titles, []string{"Go generics"}) {
+			t.Errorf("search for generics in one feed found %v", titles)
+		}
+
+		mustRun(t, s, handlerRegister, "bob")
+		if titles := search("generics"); len(titles) != 0 {
+			t.Errorf("search by a user without follows found %v", titles)
+		}
+
+		mustRun(t, s, middlewareLoggedIn(handlerSearch), "generics", "--since", "2000-01-01")
+		for _, args := range [][]string{{}, {" "}, {"generics", "--since", "yesterday"}, {"generics", "--feed", "https://example.com/missing.xml"}, {"go", "generics"}} {
+			if err := runCommand(t, s, middlewareLoggedIn(handlerSearch), args...); err == nil {
+				t.Errorf("search %q succeeded", args)
+			}
+		}
+	})
+}
+
+func TestReadTracking(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s *state) {
+		_, feedURL := newTestFeed(t, testItem{"1", "One"}, testItem{"2", "Two"})
+		_, otherURL := newTestFeed(t, testItem{"3", "Three"})
+
+		mustRun(t, s, handlerRegister, "alice")
+		mustRun(t, s, middlewareLoggedIn(handlerAddFeed), "Test", feedURL)
+		mustRun(t, s, middlewareLoggedIn(handlerAddFeed), "Other", otherURL)
+		mustRun(t, s, handlerFetch, "--all")
+
+		unread := func() []database.Post {
+			t.Helper()
+			dbPosts, err := s.db.GetUnreadPosts(context.Background(),
+				database.GetUnreadPostsParams{
+					UserID: currentUser(t, s).ID,
+					Limit:  100,
+				})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return dbPosts
+		}
+		unreadCounts := func() map[string]int64 {
+			t.Helper()
+			dbFollows, err := s.db.GetFeedFollowsForUser(context.Background(), currentUser(t, s).ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts := make(map[string]int64)
+			for _, dbFollow := range dbFollows {
+				counts[dbFollow.FeedName] = dbFollow.UnreadPosts
+			}
+			return counts
+		}
+
+		dbPosts := unread()
+		if len(dbPosts) != 3 {
+			t.Fatalf("%d unread posts after the fetch, want 3", len(dbPosts))
+		}
+
+		mustRun(t, s, middlewareLoggedIn(handlerRead), dbPosts[0].ID.String())
+		mustRun(t, s, middlewareLoggedIn(handlerRead), dbPosts[0].ID.String())
+		if n := len(unread()); n != 2 {
+			t.Errorf("%d unread posts after reading one, want 2", n)
+		}
+		mustRun(t, s, middlewareLoggedIn(handlerBrowse), "--unread", "10")
+
+		mustRun(t, s, middlewareLoggedIn(handlerRead), "--all", "--feed", feedURL)
+		if counts := unreadCounts(); counts["Test"] != 0 || counts["Other"] != 1 {
+			t.Errorf("unread counts %v after reading feed 'Test', want 0 and 1 for 'Other'", counts)
+		}
+		mustRun(t, s, middlewareLoggedIn(handlerFollowing))
+
+		// Reads are per user.
+		mustRun(t, s, handlerRegister, "bob")
+		mustRun(t, s, middlewareLoggedIn(handlerAddFollow), feedURL)
+		if counts := unreadCounts(); counts["Test"] != 2 {
+			t.Errorf("bob has %d unread posts in feed 'Test', want 2", counts["Test"])
+		}
+		// Posts of feeds bob does not follow cannot be marked.
+		otherFeed, err := s.db.GetFeed(context.Background(), otherURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, dbPost := range dbPosts {
+			if dbPost.FeedID != otherFeed.ID {
+				continue
+			}
+			if err := runCommand(t, s, middlewareLoggedIn(handlerRead), dbPost.ID.String()); err == nil {
+				t.Error("marking a post of an unfollowed feed succeeded")
+			}
+		}
+
+		mustRun(t, s, handlerLogin, "alice")
+		mustRun(t, s, middlewareLoggedIn(handlerRead), "--all")
+		if n := len(unread()); n != 0 {
+			t.Errorf("%d unread posts after reading all, want 0", n)
+		}
+
+		for _, args := range [][]string{{}, {"not-an-id"}, {"--all", "extra"}, {"--feed", feedURL}, {uuid.NewString()}} {
+			if err := runCommand(t, s, middlewareLoggedIn(handlerRead), args...); err == nil {
+				t.Errorf("read %q succeeded", args)
+			}
+		}
+	})
+}
@@ -7,13 +7,14 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content_hash)
 VALUES (
     $1,
     $2,
@@ -22,9 +23,19 @@ VALUES (
     $5,
     $6,
     $7,
-    $8
+    $8,
+    $9,
+    $10
 )
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = EXCLUDED.title,
+    url = EXCLUDED.url,
+    description = EXCLUDED.description,
+    updated_at = EXCLUDED.updated_at,
+    content_hash = EXCLUDED.content_hash,
+    revised_at = CASE WHEN posts.content_hash = '' THEN posts.revised_at ELSE EXCLUDED.updated_at END
+WHERE posts.content_hash <> EXCLUDED.content_hash
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content_hash, revised_at, (xmax = 0) AS inserted
 `
 
 type CreatePostParams struct {
@@ -36,9 +47,26 @@ type CreatePostParams struct {
 	Description string
 	PublishedAt time.Time
 	FeedID      uuid.UUID
+	Guid        string
+	ContentHash string
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
+type CreatePostRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description string
+	PublishedAt time.Time
+	FeedID      uuid.UUID
+	Guid        string
+	ContentHash string
+	RevisedAt   sql.NullTime
+	Inserted    bool
+}
+
+func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (CreatePostRow, error) {
 	row := q.db.QueryRowContext(ctx, createPost,
 		arg.ID,
 		arg.CreatedAt,
@@ -48,8 +76,10 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Guid,
+		arg.ContentHash,
 	)
-	var i Post
+	var i CreatePostRow
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
@@ -59,6 +89,10 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Guid,
+		&i.ContentHash,
+		&i.RevisedAt,
+		&i.Inserted,
 	)
 	return i, err
 }
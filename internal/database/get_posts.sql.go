@@ -12,7 +12,7 @@ import (
 )
 
 const getPosts = `-- name: GetPosts :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.guid, posts.content_hash, posts.revised_at
 FROM posts
 INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1
 ORDER BY published_at DESC
@@ -42,6 +42,9 @@ func (q *Queries) GetPosts(ctx context.Context, arg GetPostsParams) ([]Post, err
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Guid,
+			&i.ContentHash,
+			&i.RevisedAt,
 		); err != nil {
 			return nil, err
 		}
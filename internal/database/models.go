@@ -12,13 +12,34 @@ import (
 )
 
 type Feed struct {
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	UserID              uuid.UUID
+	LastFetchedAt       sql.NullTime
+	Etag                sql.NullString
+	LastModified        sql.NullString
+	NextFetchAt         sql.NullTime
+	FetchInterval       sql.NullInt32
+	ConsecutiveFailures int32
+	LastError           sql.NullString
+	LastSuccessAt       sql.NullTime
+	DisabledAt          sql.NullTime
+}
+
+type FeedFetch struct {
 	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	FeedID        uuid.UUID
+	StartedAt     time.Time
+	FinishedAt    time.Time
+	HttpStatus    sql.NullInt32
+	Bytes         int64
+	ItemsSeen     int32
+	PostsInserted int32
+	Error         sql.NullString
+	ItemsRejected int32
 }
 
 type FeedFollow struct {
@@ -38,6 +59,15 @@ type Post struct {
 	Description string
 	PublishedAt time.Time
 	FeedID      uuid.UUID
+	Guid        string
+	ContentHash string
+	RevisedAt   sql.NullTime
+}
+
+type PostRead struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt time.Time
 }
 
 type User struct {
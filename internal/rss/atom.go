@@ -0,0 +1,106 @@
+package rss
+
+import (
+	"strings"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type AtomFeed struct {
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	Link     []AtomLink  `xml:"link"`
+	Entry    []AtomEntry `xml:"entry"`
+}
+
+type AtomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Link      []AtomLink `xml:"link"`
+	Summary   AtomText   `xml:"summary"`
+	Content   AtomText   `xml:"content"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+}
+
+// AtomText is a text construct (RFC 4287, 3.1), whose xhtml form wraps its
+// markup in a <div> instead of escaping it.
+type AtomText struct {
+	Type     string `xml:"type,attr"`
+	Text     string `xml:",chardata"`
+	InnerXML string `xml:",innerxml"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+func parseAtom(data []byte) (*Feed, error) {
+	var atom AtomFeed
+	if err := unmarshalXML(data, &atom); err != nil {
+		return nil, err
+	}
+
+	feed := Feed{
+		Title:       atom.Title,
+		Link:        alternateLink(atom.Link),
+		Description: atom.Subtitle,
+		Items:       make([]Item, 0, len(atom.Entry)),
+	}
+
+	for _, entry := range atom.Entry {
+		description := entry.Summary.String()
+		if description == "" {
+			description = entry.Content.String()
+		}
+
+		pubDate := entry.Published
+		if pubDate == "" {
+			pubDate = entry.Updated
+		}
+
+		feed.Items = append(feed.Items, Item{
+			GUID:        strings.TrimSpace(entry.ID),
+			Title:       entry.Title,
+			Link:        alternateLink(entry.Link),
+			Description: description,
+			PubDate:     pubDate,
+			Updated:     entry.Updated,
+		})
+	}
+
+	return &feed, nil
+}
+
+// An Atom link without a rel attribute is an alternate link (RFC 4287, 4.2.7.2).
+func alternateLink(links []AtomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
+// String returns the text, or the markup inside the wrapping <div> of xhtml text.
+func (text AtomText) String() string {
+	if strings.TrimSpace(text.Type) != "xhtml" {
+		return text.Text
+	}
+
+	markup := strings.TrimSpace(text.InnerXML)
+	if !strings.HasPrefix(markup, "<") {
+		return markup
+	}
+	start := strings.Index(markup, ">")
+	end := strings.LastIndex(markup, "</")
+	if start < 0 || end < start {
+		return ""
+	}
+	return strings.TrimSpace(markup[start+1 : end])
+}
@@ -0,0 +1,103 @@
+package rss
+
+import (
+	"testing"
+)
+
+func TestParseAtom(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Blog</title>
+  <subtitle>Notes &amp; news</subtitle>
+  <link rel="self" href="https://example.com/atom.xml"/>
+  <link href="https://example.com/"/>
+  <entry>
+    <id> tag:example.com,2024:1 </id>
+    <title>First &amp; foremost</title>
+    <link rel="alternate" href="https://example.com/1"/>
+    <summary>Short summary</summary>
+    <content type="html">&lt;p&gt;Full content&lt;/p&gt;</content>
+    <published>2024-01-02T03:04:05Z</published>
+    <updated>2024-01-03T03:04:05Z</updated>
+  </entry>
+  <entry>
+    <id>tag:example.com,2024:2</id>
+    <title>Second</title>
+    <link rel="enclosure" href="https://example.com/2.mp3"/>
+    <content type="html">&lt;p&gt;Only content&lt;/p&gt;</content>
+    <updated>2024-02-03T03:04:05Z</updated>
+  </entry>
+  <entry>
+    <id>tag:example.com,2024:3</id>
+    <title>Third</title>
+    <link href="https://example.com/3"/>
+    <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Inline <b>markup</b></p></div></content>
+  </entry>
+</feed>`)
+
+	feed, err := ParseFeed(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if feed.Title != "Example Blog" || feed.Link != "https://example.com/" || feed.Description != "Notes & news" {
+		t.Errorf("unexpected feed: %+v", feed)
+	}
+
+	want := []Item{
+		{
+			GUID:        "tag:example.com,2024:1",
+			Title:       "First & foremost",
+			Link:        "https://example.com/1",
+			Description: "Short summary",
+			PubDate:     "2024-01-02T03:04:05Z",
+			Updated:     "2024-01-03T03:04:05Z",
+		},
+		{
+			GUID:        "tag:example.com,2024:2",
+			Title:       "Second",
+			Link:        "https://example.com/2.mp3",
+			Description: "<p>Only content</p>",
+			PubDate:     "2024-02-03T03:04:05Z",
+			Updated:     "2024-02-03T03:04:05Z",
+		},
+		{
+			GUID:        "tag:example.com,2024:3",
+			Title:       "Third",
+			Link:        "https://example.com/3",
+			Description: "<p>Inline <b>markup</b></p>",
+		},
+	}
+
+	if len(feed.Items) != len(want) {
+		t.Fatalf("got %d items, want %d", len(feed.Items), len(want))
+	}
+	for i, item := range feed.Items {
+		if item.GUID != want[i].GUID || item.Title != want[i].Title || item.Link != want[i].Link ||
+			item.Description != want[i].Description || item.PubDate != want[i].PubDate || item.Updated != want[i].Updated {
+			t.Errorf("item %d: got %+v, want %+v", i, item, want[i])
+		}
+	}
+}
+
+func TestAtomText(t *testing.T) {
+	tests := []struct {
+		name string
+		text AtomText
+		want string
+	}{
+		{"text", AtomText{Text: "plain"}, "plain"},
+		{"html", AtomText{Type: "html", Text: "<p>escaped</p>"}, "<p>escaped</p>"},
+		{"xhtml", AtomText{Type: "xhtml", InnerXML: ` <div xmlns="http://www.w3.org/1999/xhtml"> <p>kept</p> </div> `}, "<p>kept</p>"},
+		{"prefixed xhtml", AtomText{Type: "xhtml", InnerXML: `<x:div xmlns:x="http://www.w3.org/1999/xhtml"><x:p>kept</x:p></x:div>`}, "<x:p>kept</x:p>"},
+		{"empty xhtml", AtomText{Type: "xhtml", InnerXML: `<div xmlns="http://www.w3.org/1999/xhtml"/>`}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.text.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
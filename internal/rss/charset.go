@@ -0,0 +1,69 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+var xmlEncodingDecl = regexp.MustCompile(`^(\s*<\?xml[^>]*?encoding=)["'][^"']*["']`)
+
+// ParseFeedCharset parses a feed whose character set was announced out of band,
+// usually by the charset parameter of the Content-Type header. The announced
+// charset takes precedence over the XML declaration (RFC 7303, 8.1).
+func ParseFeedCharset(data []byte, charset string) (*Feed, error) {
+	if charset == "" || isJSON(data) {
+		return ParseFeed(data)
+	}
+
+	data, err := toUTF8(data, charset)
+	if err != nil {
+		return nil, err
+	}
+
+	data = xmlEncodingDecl.ReplaceAll(data, []byte(`${1}"UTF-8"`))
+
+	return ParseFeed(data)
+}
+
+func toUTF8(data []byte, charset string) ([]byte, error) {
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset %q: %w", charset, err)
+	}
+
+	if enc == unicode.UTF8 {
+		return data, nil
+	}
+
+	return enc.NewDecoder().Bytes(data)
+}
+
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	enc, err := htmlindex.Get(strings.TrimSpace(charset))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset %q: %w", charset, err)
+	}
+
+	if enc == unicode.UTF8 {
+		return input, nil
+	}
+
+	return enc.NewDecoder().Reader(input), nil
+}
+
+func newXMLDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charsetReader
+	return decoder
+}
+
+func unmarshalXML(data []byte, v any) error {
+	return newXMLDecoder(data).Decode(v)
+}
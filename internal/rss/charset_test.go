@@ -0,0 +1,66 @@
+package rss
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func encodeFeed(t *testing.T, enc encoding.Encoding, declared, title string) []byte {
+	t.Helper()
+
+	feed := `<?xml version="1.0" encoding="` + declared + `"?>
+<rss version="2.0"><channel><title>` + title + `</title>
+<item><title>` + title + `</title><description>` + title + `</description></item>
+</channel></rss>`
+
+	data, err := enc.NewEncoder().Bytes([]byte(feed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseFeedCharset(t *testing.T) {
+	tests := []struct {
+		name     string
+		enc      encoding.Encoding
+		declared string
+		charset  string
+		title    string
+	}{
+		{"latin1 declaration", charmap.ISO8859_1, "ISO-8859-1", "", "Café crème"},
+		{"latin1 header", charmap.ISO8859_1, "UTF-8", "iso-8859-1", "Café crème"},
+		{"windows-1252 declaration", charmap.Windows1252, "windows-1252", "", "“Quoted” café"},
+		{"shift_jis declaration", japanese.ShiftJIS, "Shift_JIS", "", "日本語のニュース"},
+		{"shift_jis header", japanese.ShiftJIS, "EUC-JP", "Shift_JIS", "日本語のニュース"},
+		{"utf-8 header", encoding.Nop, "UTF-8", "utf-8", "Café 日本語"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := ParseFeedCharset(encodeFeed(t, tt.enc, tt.declared, tt.title), tt.charset)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if feed.Title != tt.title || len(feed.Items) != 1 ||
+				feed.Items[0].Title != tt.title || feed.Items[0].Description != tt.title {
+				t.Errorf("got %+v, want title %q", feed, tt.title)
+			}
+		})
+	}
+}
+
+func TestParseFeedCharsetUnsupported(t *testing.T) {
+	data := encodeFeed(t, encoding.Nop, "UTF-8", "Title")
+	if _, err := ParseFeedCharset(data, "x-unknown"); err == nil {
+		t.Error("got no error for an unknown charset")
+	}
+
+	data = encodeFeed(t, encoding.Nop, "x-unknown", "Title")
+	if _, err := ParseFeed(data); err == nil {
+		t.Error("got no error for an unknown declared encoding")
+	}
+}
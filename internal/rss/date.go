@@ -0,0 +1,105 @@
+package rss
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"Mon, 2 January 2006 15:04:05 -0700",
+	"Mon, 2 January 2006 15:04:05 MST",
+	"Monday, 2 Jan 2006 15:04:05 -0700",
+	"Monday, 2 Jan 2006 15:04:05 MST",
+	"Monday, 2 January 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04 MST",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006",
+	"2 Jan 2006",
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC850,
+	time.ANSIC,
+	time.UnixDate,
+	time.RubyDate,
+}
+
+// Offsets of the zone abbreviations found in feeds. Dates are parsed in UTC, so
+// that the local time zone of the host never decides what an abbreviation means.
+var zoneOffsets = map[string]int{
+	"EST":  -5 * 60 * 60,
+	"EDT":  -4 * 60 * 60,
+	"CST":  -6 * 60 * 60,
+	"CDT":  -5 * 60 * 60,
+	"MST":  -7 * 60 * 60,
+	"MDT":  -6 * 60 * 60,
+	"PST":  -8 * 60 * 60,
+	"PDT":  -7 * 60 * 60,
+	"BST":  1 * 60 * 60,
+	"CET":  1 * 60 * 60,
+	"CEST": 2 * 60 * 60,
+	"EET":  2 * 60 * 60,
+	"EEST": 3 * 60 * 60,
+	"IST":  5*60*60 + 30*60,
+	"JST":  9 * 60 * 60,
+	"KST":  9 * 60 * 60,
+	"AEST": 10 * 60 * 60,
+	"AEDT": 11 * 60 * 60,
+}
+
+func ParseDate(value string) (time.Time, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+
+	// "UT" is a valid RFC 822 zone but is not understood by time.Parse.
+	if strings.HasSuffix(value, " UT") {
+		value += "C"
+	}
+
+	for _, layout := range dateLayouts {
+		t, err := time.ParseInLocation(layout, value, time.UTC)
+		if err != nil {
+			continue
+		}
+
+		name, offset := t.Zone()
+		if zoneOffset, ok := zoneOffsets[name]; ok && offset == 0 && zoneOffset != 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(),
+				time.FixedZone(name, zoneOffset))
+		}
+
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date format: %q", value)
+}
+
+// PublishedAt returns the first parseable item date, or fallback when none is usable.
+func (item Item) PublishedAt(fallback time.Time) time.Time {
+	for _, value := range []string{item.PubDate, item.Updated} {
+		if t, err := ParseDate(value); err == nil {
+			return t
+		}
+	}
+	return fallback
+}
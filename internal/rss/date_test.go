@@ -0,0 +1,82 @@
+package rss
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"Mon, 02 Jan 2006 15:04:05 -0700", time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"Mon, 2 Jan 2006 15:04:05 +0000", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"Mon, 2 Jan 2006 15:04 -0700", time.Date(2006, 1, 2, 22, 4, 0, 0, time.UTC)},
+		{"Mon, 2 January 2006 15:04:05 -0700", time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)},
+		{"Monday, 2 Jan 2006 15:04:05 -0700", time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)},
+		{"2 Jan 2006 15:04:05 -0700", time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)},
+		{"Mon, 2 Jan 2006", time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"  Mon,  2 Jan 2006\n15:04:05   +0000 ", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2006-01-02T15:04:05Z", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2006-01-02T15:04:05.123456789+02:00", time.Date(2006, 1, 2, 13, 4, 5, 123456789, time.UTC)},
+		{"2006-01-02T15:04:05-0700", time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)},
+		{"2006-01-02T15:04:05", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2006-01-02T15:04Z", time.Date(2006, 1, 2, 15, 4, 0, 0, time.UTC)},
+		{"2006-01-02 15:04:05 -0700", time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)},
+		{"2006-01-02 15:04:05", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2006-01-02", time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"Monday, 02-Jan-06 15:04:05 UTC", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"Mon Jan  2 15:04:05 2006", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		// Named zones that time.Parse leaves at a zero offset.
+		{"Mon, 02 Jan 2006 15:04:05 UT", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"Mon, 02 Jan 2006 15:04:05 EST", time.Date(2006, 1, 2, 20, 4, 5, 0, time.UTC)},
+		{"Mon, 02 Jan 2006 15:04:05 PDT", time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)},
+		{"Mon, 02 Jan 2006 15:04:05 CEST", time.Date(2006, 1, 2, 13, 4, 5, 0, time.UTC)},
+		{"2006-01-02 15:04:05 IST", time.Date(2006, 1, 2, 9, 34, 5, 0, time.UTC)},
+		{"2 January 2006 15:04:05 JST", time.Date(2006, 1, 2, 6, 4, 5, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDate(tt.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDateInvalid(t *testing.T) {
+	for _, value := range []string{"", "   ", "yesterday", "2006-13-45", "Mon, 32 Jan 2006 15:04:05 GMT"} {
+		if got, err := ParseDate(value); err == nil {
+			t.Errorf("ParseDate(%q) = %v, want an error", value, got)
+		}
+	}
+}
+
+func TestPublishedAt(t *testing.T) {
+	fallback := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		item Item
+		want time.Time
+	}{
+		{"published", Item{PubDate: "2006-01-02", Updated: "2007-01-02"}, time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"updated", Item{PubDate: "soon", Updated: "2007-01-02"}, time.Date(2007, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"fallback", Item{PubDate: "soon"}, fallback},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.item.PublishedAt(fallback); !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package rss
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+type FeedLink struct {
+	URL   string
+	Title string
+	Type  string
+}
+
+var ErrNoFeedFound = errors.New("no feed found")
+
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/rdf+xml":   true,
+	"application/feed+json": true,
+}
+
+var commonFeedPaths = []string{
+	"/feed",
+	"/rss",
+	"/feed.xml",
+	"/rss.xml",
+	"/atom.xml",
+	"/index.xml",
+	"/feed.json",
+	"/feed/atom",
+	"/index.rss",
+}
+
+// DiscoverFeeds returns the feeds available at pageURL. When pageURL is itself a feed
+// it is the only result; when it is a web page, the page is searched for alternate
+// links and, failing that, common feed paths on the same site are probed.
+func DiscoverFeeds(ctx context.Context, pageURL string) ([]FeedLink, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "gator")
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, newStatusError(res, time.Now())
+	}
+
+	data, err := readBody(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Redirects may have moved the page, and relative links resolve against the final URL.
+	base := res.Request.URL
+
+	contentType := res.Header.Get("Content-Type")
+	feed, err := ParseFeedCharset(data, contentTypeCharset(contentType))
+	if err == nil {
+		return []FeedLink{{URL: base.String(), Title: feed.Title}}, nil
+	}
+
+	// Only web pages are searched for feeds; a broken feed is reported rather than
+	// replaced by another feed of the same site.
+	if !isHTML(contentType, data) {
+		if isFeedContentType(contentType) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: content type %q: %v", ErrNotAFeed, contentType, err)
+	}
+
+	links := findFeedLinks(data, base)
+	if len(links) > 0 {
+		return links, nil
+	}
+
+	// Sites often serve the same feed under several of these paths, so the first
+	// one found is the only result.
+	for _, path := range commonFeedPaths {
+		candidate := base.ResolveReference(&url.URL{Path: path})
+		result, err := FetchFeed(ctx, candidate.String(), CacheValidators{})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		return []FeedLink{{URL: candidate.String(), Title: result.Feed.Title}}, nil
+	}
+
+	return nil, ErrNoFeedFound
+}
+
+// isHTML reports whether a response is a web page, sniffing the content when the
+// server sent no Content-Type.
+func isHTML(contentType string, data []byte) bool {
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+func findFeedLinks(data []byte, base *url.URL) []FeedLink {
+	links := make([]FeedLink, 0)
+	seen := make(map[string]bool)
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return links
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "base":
+				if href, err := url.Parse(attr(token, "href")); err == nil {
+					base = base.ResolveReference(href)
+				}
+			case "link":
+				if !hasToken(attr(token, "rel"), "alternate") {
+					continue
+				}
+				linkType := strings.ToLower(strings.TrimSpace(attr(token, "type")))
+				if !feedLinkTypes[linkType] {
+					continue
+				}
+				href, err := url.Parse(strings.TrimSpace(attr(token, "href")))
+				if err != nil {
+					continue
+				}
+				feedURL := base.ResolveReference(href).String()
+				if seen[feedURL] {
+					continue
+				}
+				seen[feedURL] = true
+				links = append(links, FeedLink{
+					URL:   feedURL,
+					Title: attr(token, "title"),
+					Type:  linkType,
+				})
+			case "body":
+				// Feed links belong in the head; stop before scanning the whole document.
+				return links
+			}
+		}
+	}
+}
+
+func attr(token html.Token, key string) string {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasToken(list string, value string) bool {
+	for _, field := range strings.Fields(list) {
+		if strings.EqualFold(field, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+)
+
+func TestFindFeedLinks(t *testing.T) {
+	base, err := url.Parse("https://example.com/blog/post")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		page string
+		want []FeedLink
+	}{
+		{
+			name: "absolute and relative links",
+			page: `<html><head>
+<link rel="alternate" type="application/rss+xml" title="RSS" href="https://example.com/rss.xml">
+<link rel="alternate" type="application/atom+xml" title="Atom" href="atom.xml">
+<link rel="alternate" type="application/feed+json" href="/feed.json">
+</head></html>`,
+			want: []FeedLink{
+				{URL: "https://example.com/rss.xml", Title: "RSS", Type: "application/rss+xml"},
+				{URL: "https://example.com/blog/atom.xml", Title: "Atom", Type: "application/atom+xml"},
+				{URL: "https://example.com/feed.json", Type: "application/feed+json"},
+			},
+		},
+		{
+			name: "base element",
+			page: `<head><base href="https://feeds.example.org/x/"><link rel="alternate" type="application/rss+xml" href="main.rss"></head>`,
+			want: []FeedLink{{URL: "https://feeds.example.org/x/main.rss", Type: "application/rss+xml"}},
+		},
+		{
+			name: "rel tokens and case",
+			page: `<head><link rel="Alternate Home" type=" Application/RSS+XML " href="/a.xml"></head>`,
+			want: []FeedLink{{URL: "https://example.com/a.xml", Type: "application/rss+xml"}},
+		},
+		{
+			name: "duplicates",
+			page: `<head><link rel="alternate" type="application/rss+xml" href="/a.xml"><link rel="alternate" type="application/rss+xml" title="Again" href="https://example.com/a.xml"></head>`,
+			want: []FeedLink{{URL: "https://example.com/a.xml", Type: "application/rss+xml"}},
+		},
+		{
+			name: "not feeds",
+			page: `<head>
+<link rel="stylesheet" type="text/css" href="/style.css">
+<link rel="alternate" type="text/html" hreflang="fr" href="/fr/">
+<link rel="alternate" type="application/json" href="/wp-json/wp/v2/posts/1">
+<link rel="https://api.w.org/" href="/wp-json/">
+</head>`,
+			want: []FeedLink{},
+		},
+		{
+			name: "links in the body",
+			page: `<head></head><body><link rel="alternate" type="application/rss+xml" href="/a.xml"></body>`,
+			want: []FeedLink{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findFeedLinks([]byte(tt.page), base); !slices.Equal(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscoverFeeds(t *testing.T) {
+	const feed = `<rss><channel><title>Site feed</title></channel></rss>`
+
+	var pages map[string]struct{ contentType, body string }
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", page.contentType)
+		w.Write([]byte(page.body))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		pages   map[string]struct{ contentType, body string }
+		want    []FeedLink
+		wantErr error
+	}{
+		{
+			name:  "feed",
+			path:  "/feed.xml",
+			pages: map[string]struct{ contentType, body string }{"/feed.xml": {"application/rss+xml", feed}},
+			want:  []FeedLink{{URL: server.URL + "/feed.xml", Title: "Site feed"}},
+		},
+		{
+			name: "page with links",
+			path: "/",
+			pages: map[string]struct{ contentType, body string }{
+				"/":        {"text/html", `<head><link rel="alternate" type="application/rss+xml" title="Posts" href="/posts.xml"></head>`},
+				"/rss.xml": {"application/rss+xml", feed},
+			},
+			want: []FeedLink{{URL: server.URL + "/posts.xml", Title: "Posts", Type: "application/rss+xml"}},
+		},
+		{
+			name: "page without links",
+			path: "/",
+			pages: map[string]struct{ contentType, body string }{
+				"/":         {"text/html; charset=utf-8", `<html><head></head></html>`},
+				"/feed":     {"application/rss+xml", feed},
+				"/rss":      {"application/rss+xml", feed},
+				"/feed.xml": {"application/rss+xml", feed},
+			},
+			want: []FeedLink{{URL: server.URL + "/feed", Title: "Site feed"}},
+		},
+		{
+			name: "broken feed",
+			path: "/feed.json",
+			pages: map[string]struct{ contentType, body string }{
+				"/feed.json": {"application/feed+json", `{"version": "2.0", "items": []}`},
+				"/rss.xml":   {"application/rss+xml", feed},
+			},
+			wantErr: ErrUnknownFormat,
+		},
+		{
+			name: "not a page",
+			path: "/image.png",
+			pages: map[string]struct{ contentType, body string }{
+				"/image.png": {"image/png", "\x89PNG"},
+				"/rss.xml":   {"application/rss+xml", feed},
+			},
+			wantErr: ErrNotAFeed,
+		},
+		{
+			name:    "no feed",
+			path:    "/",
+			pages:   map[string]struct{ contentType, body string }{"/": {"text/html", `<html></html>`}},
+			wantErr: ErrNoFeedFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages = tt.pages
+
+			links, err := DiscoverFeeds(context.Background(), server.URL+tt.path)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("got %+v, %v, want %v", links, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(links, tt.want) {
+				t.Errorf("got %+v, want %+v", links, tt.want)
+			}
+		})
+	}
+}
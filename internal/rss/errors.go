@@ -0,0 +1,80 @@
+package rss
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrNotFound    = errors.New("feed not found")
+	ErrGone        = errors.New("feed is gone")
+	ErrRateLimited = errors.New("rate limited")
+	ErrServer      = errors.New("server error")
+	ErrHTTPStatus  = errors.New("unexpected HTTP status")
+	ErrNotAFeed    = errors.New("not a feed")
+	ErrTooLarge    = errors.New("response too large")
+)
+
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("%v: HTTP %d %s", e.Err, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %s)", e.RetryAfter)
+	}
+	return msg
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+func newStatusError(res *http.Response, now time.Time) *StatusError {
+	statusErr := StatusError{
+		StatusCode: res.StatusCode,
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		statusErr.Err = ErrNotFound
+	case res.StatusCode == http.StatusGone:
+		statusErr.Err = ErrGone
+	case res.StatusCode == http.StatusTooManyRequests:
+		statusErr.Err = ErrRateLimited
+		statusErr.RetryAfter = parseRetryAfter(res.Header.Get("Retry-After"), now)
+	case res.StatusCode >= 500:
+		statusErr.Err = ErrServer
+		statusErr.RetryAfter = parseRetryAfter(res.Header.Get("Retry-After"), now)
+	default:
+		statusErr.Err = ErrHTTPStatus
+	}
+
+	return &statusErr
+}
+
+// Retry-After is either a number of seconds or an HTTP date (RFC 9110, 10.2.3).
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
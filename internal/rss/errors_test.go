@@ -0,0 +1,91 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewStatusError(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		status     int
+		retryAfter string
+		wantErr    error
+		wantRetry  time.Duration
+	}{
+		{http.StatusNotFound, "", ErrNotFound, 0},
+		{http.StatusGone, "", ErrGone, 0},
+		{http.StatusTooManyRequests, "120", ErrRateLimited, 2 * time.Minute},
+		{http.StatusTooManyRequests, "Tue, 02 Jan 2024 04:04:05 GMT", ErrRateLimited, time.Hour},
+		{http.StatusTooManyRequests, "Tue, 02 Jan 2024 02:04:05 GMT", ErrRateLimited, 0},
+		{http.StatusTooManyRequests, "-1", ErrRateLimited, 0},
+		{http.StatusTooManyRequests, "soon", ErrRateLimited, 0},
+		{http.StatusServiceUnavailable, "30", ErrServer, 30 * time.Second},
+		{http.StatusInternalServerError, "", ErrServer, 0},
+		{http.StatusForbidden, "30", ErrHTTPStatus, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status)+" "+tt.retryAfter, func(t *testing.T) {
+			res := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.retryAfter != "" {
+				res.Header.Set("Retry-After", tt.retryAfter)
+			}
+
+			err := newStatusError(res, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+			if err.StatusCode != tt.status || err.RetryAfter != tt.wantRetry {
+				t.Errorf("got status %d retry %v, want %d %v", err.StatusCode, err.RetryAfter, tt.status, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestFetchFeedStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantErr     error
+	}{
+		{"rss", http.StatusOK, "application/rss+xml", `<rss><channel><title>T</title></channel></rss>`, nil},
+		{"untyped feed", http.StatusOK, "text/plain", `<rss><channel><title>T</title></channel></rss>`, nil},
+		{"html page", http.StatusOK, "text/html", `<html><body>Hi</body></html>`, ErrNotAFeed},
+		{"broken feed", http.StatusOK, "application/rss+xml", `<html><body>Hi</body></html>`, ErrUnknownFormat},
+		{"not found", http.StatusNotFound, "text/html", `missing`, ErrNotFound},
+		{"rate limited", http.StatusTooManyRequests, "text/plain", `slow down`, ErrRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			result, err := FetchFeed(context.Background(), server.URL, CacheValidators{})
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if result.StatusCode != tt.status || result.Feed.Title != "T" {
+					t.Errorf("unexpected result: %+v", result)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package rss
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	TTL         time.Duration
+	Items       []Item
+}
+
+type Item struct {
+	GUID        string
+	Title       string
+	Link        string
+	Description string
+	PubDate     string
+	Updated     string
+	Author      string
+	Enclosures  []Enclosure
+}
+
+type Enclosure struct {
+	URL    string
+	Type   string
+	Length int64
+}
+
+var ErrUnknownFormat = errors.New("unknown feed format")
+
+func ParseFeed(data []byte) (*Feed, error) {
+	// JSON strings carry no XML entity escaping, so only XML formats are unescaped.
+	if isJSON(data) {
+		return parseJSONFeed(data)
+	}
+
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var feed *Feed
+	switch {
+	case root.Local == "rss":
+		feed, err = parseRSS(data)
+	case root.Local == "feed" && root.Space == atomNamespace:
+		feed, err = parseAtom(data)
+	case root.Local == "RDF" && root.Space == rdfNamespace:
+		feed, err = parseRDF(data)
+	default:
+		return nil, fmt.Errorf("%w: root element <%s>", ErrUnknownFormat, root.Local)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	feed.unescape()
+
+	return feed, nil
+}
+
+func isJSON(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, "\ufeff \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+func rootElement(data []byte) (xml.Name, error) {
+	decoder := newXMLDecoder(data)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				return xml.Name{}, ErrUnknownFormat
+			}
+			return xml.Name{}, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
+}
+
+var updatePeriods = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+	"yearly":  365 * 24 * time.Hour,
+}
+
+// updateInterval combines the RSS <ttl> element (in minutes) and the syndication
+// module's updatePeriod and updateFrequency into the longest interval they announce.
+func updateInterval(ttl, period, frequency string) time.Duration {
+	var interval time.Duration
+
+	if minutes, err := strconv.Atoi(strings.TrimSpace(ttl)); err == nil && minutes > 0 {
+		interval = time.Duration(minutes) * time.Minute
+	}
+
+	if duration, ok := updatePeriods[strings.ToLower(strings.TrimSpace(period))]; ok {
+		times, err := strconv.Atoi(strings.TrimSpace(frequency))
+		if err != nil || times < 1 {
+			times = 1
+		}
+		interval = max(interval, duration/time.Duration(times))
+	}
+
+	return interval
+}
+
+// Key identifies the item within its feed: the publisher's GUID when present,
+// otherwise a hash of the link, or of the title and description for items without one.
+// A GUID that is only the item's link is keyed like the link, which is also how
+// posts stored before GUIDs were tracked have been keyed.
+func (item Item) Key() string {
+	link := strings.TrimSpace(item.Link)
+	if guid := strings.TrimSpace(item.GUID); guid != "" && guid != link {
+		return guid
+	}
+
+	source := link
+	if source == "" {
+		source = item.Title + "\n" + item.Description
+	}
+
+	sum := sha256.Sum256([]byte(source))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// ContentHash changes whenever the publisher edits the visible content of the item.
+func (item Item) ContentHash() string {
+	sum := sha256.Sum256([]byte(item.Title + "\x00" + item.Link + "\x00" + item.Description))
+	return hex.EncodeToString(sum[:])
+}
+
+func (feed *Feed) unescape() {
+	feed.Title = html.UnescapeString(feed.Title)
+	feed.Description = html.UnescapeString(feed.Description)
+
+	for i := range feed.Items {
+		feed.Items[i].Title = html.UnescapeString(feed.Items[i].Title)
+		feed.Items[i].Description = html.UnescapeString(feed.Items[i].Description)
+	}
+}
@@ -0,0 +1,76 @@
+package rss
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestItemKey(t *testing.T) {
+	hash := func(source string) string {
+		sum := sha256.Sum256([]byte(source))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	tests := []struct {
+		name string
+		item Item
+		want string
+	}{
+		{"guid", Item{GUID: " tag:example.com,2024:1 ", Link: "https://example.com/1"}, "tag:example.com,2024:1"},
+		{"guid is the link", Item{GUID: "https://example.com/1", Link: " https://example.com/1\n"}, hash("https://example.com/1")},
+		{"link", Item{Link: " https://example.com/1 "}, hash("https://example.com/1")},
+		{"title and description", Item{Title: "Title", Description: "Text"}, hash("Title\nText")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.item.Key(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateInterval(t *testing.T) {
+	tests := []struct {
+		ttl, period, frequency string
+		want                   time.Duration
+	}{
+		{"", "", "", 0},
+		{"60", "", "", time.Hour},
+		{" 15 ", "", "", 15 * time.Minute},
+		{"0", "", "", 0},
+		{"-5", "", "", 0},
+		{"soon", "", "", 0},
+		{"", "hourly", "", time.Hour},
+		{"", "daily", "4", 6 * time.Hour},
+		{"", " Weekly ", "", 7 * 24 * time.Hour},
+		{"", "monthly", "0", 30 * 24 * time.Hour},
+		{"", "yearly", "x", 365 * 24 * time.Hour},
+		{"", "fortnightly", "", 0},
+		{"120", "hourly", "2", 2 * time.Hour},
+		{"10", "daily", "", 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ttl+"/"+tt.period+"/"+tt.frequency, func(t *testing.T) {
+			if got := updateInterval(tt.ttl, tt.period, tt.frequency); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFeedTTL(t *testing.T) {
+	feed, err := ParseFeed([]byte(`<rss xmlns:sy="http://purl.org/rss/1.0/modules/syndication/"><channel>
+<title>T</title><ttl>30</ttl><sy:updatePeriod>weekly</sy:updatePeriod><sy:updateFrequency>7</sy:updateFrequency>
+</channel></rss>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.TTL != 24*time.Hour {
+		t.Errorf("got TTL %s, want %s", feed.TTL, 24*time.Hour)
+	}
+}
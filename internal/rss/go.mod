@@ -1,3 +1,8 @@
 module rss
 
 go 1.24.1
+
+require (
+	golang.org/x/net v0.43.0
+	golang.org/x/text v0.28.0
+)
@@ -0,0 +1,131 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const jsonFeedVersionPrefix = "https://jsonfeed.org/version/"
+
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            JSONFeedID           `json:"id"`
+	URL           string               `json:"url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Author        *JSONFeedAuthor      `json:"author"`
+	Authors       []JSONFeedAuthor     `json:"authors"`
+	Attachments   []JSONFeedAttachment `json:"attachments"`
+}
+
+// JSONFeedID is an item id, which readers must coerce to a string when a feed
+// presents it as a number or another type.
+type JSONFeedID string
+
+func (id *JSONFeedID) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch value := value.(type) {
+	case nil:
+		*id = ""
+	case string:
+		*id = JSONFeedID(value)
+	default:
+		*id = JSONFeedID(bytes.TrimSpace(data))
+	}
+	return nil
+}
+
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type JSONFeedAttachment struct {
+	URL         string `json:"url"`
+	MIMEType    string `json:"mime_type"`
+	SizeInBytes int64  `json:"size_in_bytes"`
+}
+
+func parseJSONFeed(data []byte) (*Feed, error) {
+	var jsonFeed JSONFeed
+	if err := json.Unmarshal(data, &jsonFeed); err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(jsonFeed.Version, jsonFeedVersionPrefix) {
+		return nil, fmt.Errorf("%w: unsupported JSON Feed version %q", ErrUnknownFormat, jsonFeed.Version)
+	}
+
+	feed := Feed{
+		Title:       jsonFeed.Title,
+		Link:        jsonFeed.HomePageURL,
+		Description: jsonFeed.Description,
+		Items:       make([]Item, 0, len(jsonFeed.Items)),
+	}
+
+	for _, jsonItem := range jsonFeed.Items {
+		description := jsonItem.ContentHTML
+		if description == "" {
+			description = jsonItem.ContentText
+		}
+		if description == "" {
+			description = jsonItem.Summary
+		}
+
+		pubDate := jsonItem.DatePublished
+		if pubDate == "" {
+			pubDate = jsonItem.DateModified
+		}
+
+		// JSON Feed 1.0 used a single author object, replaced by authors in 1.1.
+		authors := jsonItem.Authors
+		if len(authors) == 0 && jsonItem.Author != nil {
+			authors = []JSONFeedAuthor{*jsonItem.Author}
+		}
+		names := make([]string, 0, len(authors))
+		for _, author := range authors {
+			if author.Name != "" {
+				names = append(names, author.Name)
+			}
+		}
+
+		enclosures := make([]Enclosure, 0, len(jsonItem.Attachments))
+		for _, attachment := range jsonItem.Attachments {
+			enclosures = append(enclosures, Enclosure{
+				URL:    attachment.URL,
+				Type:   attachment.MIMEType,
+				Length: attachment.SizeInBytes,
+			})
+		}
+
+		feed.Items = append(feed.Items, Item{
+			GUID:        string(jsonItem.ID),
+			Title:       jsonItem.Title,
+			Link:        jsonItem.URL,
+			Description: description,
+			PubDate:     pubDate,
+			Updated:     jsonItem.DateModified,
+			Author:      strings.Join(names, ", "),
+			Enclosures:  enclosures,
+		})
+	}
+
+	return &feed, nil
+}
@@ -0,0 +1,96 @@
+package rss
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseJSONFeed(t *testing.T) {
+	data := []byte(`{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "Example Blog",
+  "home_page_url": "https://example.com/",
+  "description": "Notes",
+  "items": [
+    {
+      "id": "1",
+      "url": "https://example.com/1",
+      "title": "First",
+      "content_html": "<p>HTML &amp; more</p>",
+      "content_text": "Text",
+      "date_published": "2024-01-02T03:04:05Z",
+      "date_modified": "2024-01-03T03:04:05Z",
+      "authors": [{"name": "Ann"}, {"url": "https://example.com/anon"}, {"name": "Bob"}],
+      "attachments": [{"url": "https://example.com/1.mp3", "mime_type": "audio/mpeg", "size_in_bytes": 1024}]
+    },
+    {
+      "id": 123,
+      "url": "https://example.com/2",
+      "title": "Second",
+      "summary": "Summary only",
+      "date_modified": "2024-02-03T03:04:05Z",
+      "author": {"name": "Carol"}
+    }
+  ]
+}`)
+
+	feed, err := ParseFeed(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if feed.Title != "Example Blog" || feed.Link != "https://example.com/" || feed.Description != "Notes" {
+		t.Errorf("unexpected feed: %+v", feed)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("got %d items, want 2", len(feed.Items))
+	}
+
+	first := feed.Items[0]
+	// JSON strings are not unescaped like XML text.
+	if first.GUID != "1" || first.Description != "<p>HTML &amp; more</p>" || first.Author != "Ann, Bob" ||
+		first.PubDate != "2024-01-02T03:04:05Z" || first.Updated != "2024-01-03T03:04:05Z" {
+		t.Errorf("unexpected first item: %+v", first)
+	}
+	if len(first.Enclosures) != 1 || first.Enclosures[0] != (Enclosure{URL: "https://example.com/1.mp3", Type: "audio/mpeg", Length: 1024}) {
+		t.Errorf("unexpected enclosures: %+v", first.Enclosures)
+	}
+
+	second := feed.Items[1]
+	if second.GUID != "123" || second.Description != "Summary only" || second.Author != "Carol" ||
+		second.PubDate != "2024-02-03T03:04:05Z" {
+		t.Errorf("unexpected second item: %+v", second)
+	}
+}
+
+func TestJSONFeedID(t *testing.T) {
+	tests := []struct {
+		item string
+		want string
+	}{
+		{`{"id": "abc"}`, "abc"},
+		{`{"id": 123}`, "123"},
+		{`{"id": 1.5}`, "1.5"},
+		{`{"id": null}`, ""},
+		{`{}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.item, func(t *testing.T) {
+			feed, err := ParseFeed([]byte(`{"version": "https://jsonfeed.org/version/1", "items": [` + tt.item + `]}`))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := feed.Items[0].GUID; got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJSONFeedVersion(t *testing.T) {
+	_, err := ParseFeed([]byte(`{"version": "1.0", "items": []}`))
+	if !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("got %v, want %v", err, ErrUnknownFormat)
+	}
+}
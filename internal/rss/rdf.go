@@ -0,0 +1,52 @@
+package rss
+
+const rdfNamespace = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+
+type RDFFeed struct {
+	Channel struct {
+		Title           string `xml:"title"`
+		Link            string `xml:"link"`
+		Description     string `xml:"description"`
+		Date            string `xml:"http://purl.org/dc/elements/1.1/ date"`
+		UpdatePeriod    string `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+		UpdateFrequency string `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
+	} `xml:"channel"`
+	Item []RDFItem `xml:"item"`
+}
+
+type RDFItem struct {
+	About       string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+}
+
+func parseRDF(data []byte) (*Feed, error) {
+	var rdf RDFFeed
+	if err := unmarshalXML(data, &rdf); err != nil {
+		return nil, err
+	}
+
+	feed := Feed{
+		Title:       rdf.Channel.Title,
+		Link:        rdf.Channel.Link,
+		Description: rdf.Channel.Description,
+		TTL:         updateInterval("", rdf.Channel.UpdatePeriod, rdf.Channel.UpdateFrequency),
+		Items:       make([]Item, 0, len(rdf.Item)),
+	}
+
+	for _, item := range rdf.Item {
+		feed.Items = append(feed.Items, Item{
+			GUID:        item.About,
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			PubDate:     item.Date,
+			Author:      item.Creator,
+		})
+	}
+
+	return &feed, nil
+}
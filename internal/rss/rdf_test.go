@@ -0,0 +1,56 @@
+package rss
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRDF(t *testing.T) {
+	data := []byte(`<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+  xmlns="http://purl.org/rss/1.0/"
+  xmlns:dc="http://purl.org/dc/elements/1.1/"
+  xmlns:sy="http://purl.org/rss/1.0/modules/syndication/">
+  <channel rdf:about="https://example.com/">
+    <title>Example &amp; Co</title>
+    <link>https://example.com/</link>
+    <description>Notes</description>
+    <sy:updatePeriod>daily</sy:updatePeriod>
+    <sy:updateFrequency>4</sy:updateFrequency>
+  </channel>
+  <item rdf:about="https://example.com/1">
+    <title>First</title>
+    <link>https://example.com/1</link>
+    <description>&lt;p&gt;One&lt;/p&gt;</description>
+    <dc:date>2024-01-02T03:04:05+01:00</dc:date>
+    <dc:creator>Ann</dc:creator>
+  </item>
+  <item rdf:about="https://example.com/2">
+    <title>Second</title>
+    <link>https://example.com/2</link>
+  </item>
+</rdf:RDF>`)
+
+	feed, err := ParseFeed(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if feed.Title != "Example & Co" || feed.Link != "https://example.com/" || feed.TTL != 6*time.Hour {
+		t.Errorf("unexpected feed: %+v", feed)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("got %d items, want 2", len(feed.Items))
+	}
+
+	first := feed.Items[0]
+	if first.GUID != "https://example.com/1" || first.Description != "<p>One</p>" ||
+		first.PubDate != "2024-01-02T03:04:05+01:00" || first.Author != "Ann" {
+		t.Errorf("unexpected first item: %+v", first)
+	}
+
+	second := feed.Items[1]
+	if second.GUID != "https://example.com/2" || second.Title != "Second" || second.PubDate != "" {
+		t.Errorf("unexpected second item: %+v", second)
+	}
+}
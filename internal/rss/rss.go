@@ -2,18 +2,33 @@ package rss
 
 import (
 	"context"
-	"encoding/xml"
-	"html"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
+const (
+	// fetchTimeout bounds a whole request including its body, so that a server
+	// that stops answering cannot hold a fetch, and the host's slot, forever.
+	fetchTimeout = 30 * time.Second
+	maxBodySize  = 10 << 20
+)
+
+var httpClient = &http.Client{Timeout: fetchTimeout}
+
 type RSSFeed struct {
 	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
+		Title           string    `xml:"title"`
+		Link            string    `xml:"link"`
+		Description     string    `xml:"description"`
+		TTL             string    `xml:"ttl"`
+		UpdatePeriod    string    `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+		UpdateFrequency string    `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
+		Item            []RSSItem `xml:"item"`
 	} `xml:"channel"`
 }
 
@@ -22,41 +37,161 @@ type RSSItem struct {
 	Link        string `xml:"link"`
 	Description string `xml:"description"`
 	PubDate     string `xml:"pubDate"`
+	GUID        string `xml:"guid"`
+	DCDate      string `xml:"http://purl.org/dc/elements/1.1/ date"`
 }
 
-func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+type CacheValidators struct {
+	ETag         string
+	LastModified string
+}
+
+type FetchResult struct {
+	Feed        *Feed
+	Cache       CacheValidators
+	NotModified bool
+	MaxAge      time.Duration
+	StatusCode  int
+	Bytes       int64
+}
+
+func FetchFeed(ctx context.Context, feedURL string, cache CacheValidators) (*FetchResult, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("User-Agent", "gator")
+	if cache.ETag != "" {
+		req.Header.Set("If-None-Match", cache.ETag)
+	}
+	if cache.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cache.LastModified)
+	}
 
-	client := http.Client{}
-
-	res, err := client.Do(req)
+	res, err := httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 
-	data, err := io.ReadAll(res.Body)
+	result := FetchResult{
+		Cache: CacheValidators{
+			ETag:         res.Header.Get("ETag"),
+			LastModified: res.Header.Get("Last-Modified"),
+		},
+		MaxAge:     cacheMaxAge(res.Header.Get("Cache-Control")),
+		StatusCode: res.StatusCode,
+	}
+
+	if res.StatusCode == http.StatusNotModified {
+		// A 304 response may omit the validators, in which case the previous ones still apply.
+		if result.Cache.ETag == "" {
+			result.Cache.ETag = cache.ETag
+		}
+		if result.Cache.LastModified == "" {
+			result.Cache.LastModified = cache.LastModified
+		}
+		result.NotModified = true
+		return &result, nil
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, newStatusError(res, time.Now())
+	}
+
+	data, err := readBody(res.Body)
 	if err != nil {
 		return nil, err
 	}
+	result.Bytes = int64(len(data))
 
-	var feed RSSFeed
-	if err = xml.Unmarshal(data, &feed); err != nil {
+	contentType := res.Header.Get("Content-Type")
+	result.Feed, err = ParseFeedCharset(data, contentTypeCharset(contentType))
+	if err != nil {
+		if !isFeedContentType(contentType) {
+			return nil, fmt.Errorf("%w: content type %q: %v", ErrNotAFeed, contentType, err)
+		}
 		return nil, err
 	}
 
-	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
-	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
+	return &result, nil
+}
 
-	for _, item := range feed.Channel.Item {
-		item.Title = html.UnescapeString(item.Title)
-		item.Description = html.UnescapeString(item.Description)
+// readBody reads a response body of at most maxBodySize bytes.
+func readBody(body io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxBodySize {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, maxBodySize)
+	}
+	return data, nil
+}
+
+func parseRSS(data []byte) (*Feed, error) {
+	var rss RSSFeed
+	if err := unmarshalXML(data, &rss); err != nil {
+		return nil, err
+	}
+
+	feed := Feed{
+		Title:       rss.Channel.Title,
+		Link:        rss.Channel.Link,
+		Description: rss.Channel.Description,
+		TTL:         updateInterval(rss.Channel.TTL, rss.Channel.UpdatePeriod, rss.Channel.UpdateFrequency),
+		Items:       make([]Item, 0, len(rss.Channel.Item)),
+	}
+
+	for _, item := range rss.Channel.Item {
+		feed.Items = append(feed.Items, Item{
+			GUID:        item.GUID,
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			PubDate:     item.PubDate,
+			Updated:     item.DCDate,
+		})
 	}
 
 	return &feed, nil
 }
+
+func isFeedContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch mediaType {
+	case "application/rss+xml", "application/atom+xml", "application/rdf+xml",
+		"application/feed+json", "application/json", "application/xml", "text/xml":
+		return true
+	}
+
+	return false
+}
+
+func contentTypeCharset(contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return params["charset"]
+}
+
+func cacheMaxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if !ok || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+		if err != nil || seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
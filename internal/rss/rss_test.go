@@ -0,0 +1,158 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchFeedConditional(t *testing.T) {
+	const (
+		etag         = `"v2"`
+		lastModified = "Tue, 02 Jan 2024 03:04:05 GMT"
+	)
+
+	tests := []struct {
+		name            string
+		cache           CacheValidators
+		headers         map[string]string
+		wantNotModified bool
+		wantCache       CacheValidators
+	}{
+		{
+			name:      "first fetch",
+			headers:   map[string]string{"ETag": etag, "Last-Modified": lastModified},
+			wantCache: CacheValidators{ETag: etag, LastModified: lastModified},
+		},
+		{
+			name:            "etag matches",
+			cache:           CacheValidators{ETag: etag},
+			wantNotModified: true,
+			wantCache:       CacheValidators{ETag: etag},
+		},
+		{
+			name:            "not modified since",
+			cache:           CacheValidators{LastModified: lastModified},
+			wantNotModified: true,
+			wantCache:       CacheValidators{LastModified: lastModified},
+		},
+		{
+			name:            "validators replaced on 304",
+			cache:           CacheValidators{ETag: etag, LastModified: lastModified},
+			headers:         map[string]string{"ETag": `"v3"`},
+			wantNotModified: true,
+			wantCache:       CacheValidators{ETag: `"v3"`, LastModified: lastModified},
+		},
+		{
+			name:      "etag changed",
+			cache:     CacheValidators{ETag: `"v1"`},
+			headers:   map[string]string{"ETag": etag},
+			wantCache: CacheValidators{ETag: etag},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotETag, gotLastModified string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotETag, gotLastModified = r.Header.Get("If-None-Match"), r.Header.Get("If-Modified-Since")
+				for name, value := range tt.headers {
+					w.Header().Set(name, value)
+				}
+				if gotETag == etag || (gotETag == "" && gotLastModified == lastModified) {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Header().Set("Content-Type", "application/rss+xml")
+				w.Write([]byte(`<rss><channel><title>T</title><item><title>One</title></item></channel></rss>`))
+			}))
+			defer server.Close()
+
+			result, err := FetchFeed(context.Background(), server.URL, tt.cache)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gotETag != tt.cache.ETag || gotLastModified != tt.cache.LastModified {
+				t.Errorf("sent If-None-Match %q and If-Modified-Since %q, want %q and %q",
+					gotETag, gotLastModified, tt.cache.ETag, tt.cache.LastModified)
+			}
+			if result.NotModified != tt.wantNotModified || result.Cache != tt.wantCache {
+				t.Errorf("got not modified %v with %+v, want %v with %+v",
+					result.NotModified, result.Cache, tt.wantNotModified, tt.wantCache)
+			}
+			if tt.wantNotModified && (result.Feed != nil || result.StatusCode != http.StatusNotModified) {
+				t.Errorf("a 304 response returned %+v", result)
+			}
+			if !tt.wantNotModified && (result.Feed == nil || len(result.Feed.Items) != 1) {
+				t.Errorf("got feed %+v, want one item", result.Feed)
+			}
+		})
+	}
+}
+
+func TestFetchFeedLimits(t *testing.T) {
+	t.Run("timeout", func(t *testing.T) {
+		timeout := httpClient.Timeout
+		httpClient.Timeout = 100 * time.Millisecond
+		defer func() { httpClient.Timeout = timeout }()
+
+		// The server sends its headers and then never finishes the body.
+		done := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/rss+xml")
+			w.Write([]byte("<rss>"))
+			w.(http.Flusher).Flush()
+			<-done
+		}))
+		defer server.Close()
+		defer close(done)
+
+		if _, err := FetchFeed(context.Background(), server.URL, CacheValidators{}); err == nil {
+			t.Error("fetching from a server that never answers succeeded")
+		}
+		if _, err := DiscoverFeeds(context.Background(), server.URL); err == nil {
+			t.Error("discovering from a server that never answers succeeded")
+		}
+	})
+
+	t.Run("size", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/rss+xml")
+			w.Write([]byte("<rss><channel><title>" + strings.Repeat("x", maxBodySize) + "</title></channel></rss>"))
+		}))
+		defer server.Close()
+
+		if _, err := FetchFeed(context.Background(), server.URL, CacheValidators{}); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("got %v, want %v", err, ErrTooLarge)
+		}
+	})
+}
+
+func TestCacheMaxAge(t *testing.T) {
+	tests := []struct {
+		cacheControl string
+		want         time.Duration
+	}{
+		{"", 0},
+		{"max-age=300", 5 * time.Minute},
+		{"public, Max-Age=60, must-revalidate", time.Minute},
+		{`max-age="120"`, 2 * time.Minute},
+		{"no-cache", 0},
+		{"s-maxage=600", 0},
+		{"max-age=-1", 0},
+		{"max-age=soon", 0},
+		{"max-age", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cacheControl, func(t *testing.T) {
+			if got := cacheMaxAge(tt.cacheControl); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,753 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"internal/database"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// memStore keeps everything in memory, following the behavior of the SQL queries
+// including their unique keys, foreign keys and ordering. Transactions are not
+// isolated from each other; a failed one restores the data it started from.
+type memStore struct {
+	mu   sync.Mutex
+	data memData
+}
+
+var _ Store = (*memStore)(nil)
+
+type memData struct {
+	users   []database.User
+	feeds   []database.Feed
+	follows []database.FeedFollow
+	posts   []database.Post
+	fetches []database.FeedFetch
+	reads   []database.PostRead
+}
+
+func newMemStore() *memStore {
+	return &memStore{}
+}
+
+func (d memData) clone() memData {
+	return memData{
+		users:   slices.Clone(d.users),
+		feeds:   slices.Clone(d.feeds),
+		follows: slices.Clone(d.follows),
+		posts:   slices.Clone(d.posts),
+		fetches: slices.Clone(d.fetches),
+		reads:   slices.Clone(d.reads),
+	}
+}
+
+func (d *memData) user(id uuid.UUID) *database.User {
+	for i := range d.users {
+		if d.users[i].ID == id {
+			return &d.users[i]
+		}
+	}
+	return nil
+}
+
+func (d *memData) feed(id uuid.UUID) *database.Feed {
+	for i := range d.feeds {
+		if d.feeds[i].ID == id {
+			return &d.feeds[i]
+		}
+	}
+	return nil
+}
+
+func (d *memData) isFollowing(userID, feedID uuid.UUID) bool {
+	return slices.ContainsFunc(d.follows, func(follow database.FeedFollow) bool {
+		return follow.UserID == userID && follow.FeedID == feedID
+	})
+}
+
+func (d *memData) isRead(userID, postID uuid.UUID) bool {
+	return slices.ContainsFunc(d.reads, func(read database.PostRead) bool {
+		return read.UserID == userID && read.PostID == postID
+	})
+}
+
+func (d *memData) feedByURL(url string) *database.Feed {
+	for i := range d.feeds {
+		if d.feeds[i].Url == url {
+			return &d.feeds[i]
+		}
+	}
+	return nil
+}
+
+func (m *memStore) InTx(ctx context.Context, fn func(tx Store) error) error {
+	return m.Savepoint(ctx, func() error { return fn(m) })
+}
+
+func (m *memStore) Savepoint(ctx context.Context, fn func() error) error {
+	m.mu.Lock()
+	saved := m.data.clone()
+	m.mu.Unlock()
+
+	if err := fn(); err != nil {
+		m.mu.Lock()
+		m.data = saved
+		m.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+func (m *memStore) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, user := range m.data.users {
+		if user.Name == arg.Name {
+			return database.User{}, fmt.Errorf("user %s already exists", arg.Name)
+		}
+	}
+
+	user := database.User{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		Name:      arg.Name,
+	}
+	m.data.users = append(m.data.users, user)
+	return user, nil
+}
+
+func (m *memStore) GetUser(ctx context.Context, name string) (database.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, user := range m.data.users {
+		if user.Name == name {
+			return user, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (m *memStore) GetUsers(ctx context.Context) ([]database.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.data.users), nil
+}
+
+func (m *memStore) ResetUsers(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Every row depends on a user, so deleting them all cascades to everything.
+	m.data = memData{}
+	return nil
+}
+
+func (m *memStore) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.data.feedByURL(arg.Url) != nil {
+		return database.Feed{}, fmt.Errorf("feed %s already exists", arg.Url)
+	}
+	if m.data.user(arg.UserID) == nil {
+		return database.Feed{}, fmt.Errorf("user %s does not exist", arg.UserID)
+	}
+
+	feed := database.Feed{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		Name:      arg.Name,
+		Url:       arg.Url,
+		UserID:    arg.UserID,
+	}
+	m.data.feeds = append(m.data.feeds, feed)
+	return feed, nil
+}
+
+func (m *memStore) GetFeed(ctx context.Context, url string) (database.Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if feed := m.data.feedByURL(url); feed != nil {
+		return *feed, nil
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (m *memStore) GetFeeds(ctx context.Context) ([]database.GetFeedsRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows := make([]database.GetFeedsRow, 0, len(m.data.feeds))
+	for _, feed := range m.data.feeds {
+		row := database.GetFeedsRow{
+			ID:                  feed.ID,
+			CreatedAt:           feed.CreatedAt,
+			UpdatedAt:           feed.UpdatedAt,
+			Name:                feed.Name,
+			Url:                 feed.Url,
+			UserID:              feed.UserID,
+			LastFetchedAt:       feed.LastFetchedAt,
+			Etag:                feed.Etag,
+			LastModified:        feed.LastModified,
+			NextFetchAt:         feed.NextFetchAt,
+			FetchInterval:       feed.FetchInterval,
+			ConsecutiveFailures: feed.ConsecutiveFailures,
+			LastError:           feed.LastError,
+			LastSuccessAt:       feed.LastSuccessAt,
+			DisabledAt:          feed.DisabledAt,
+		}
+		if user := m.data.user(feed.UserID); user != nil {
+			row.UserName = sql.NullString{String: user.Name, Valid: true}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (m *memStore) GetEnabledFeeds(ctx context.Context) ([]database.Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var feeds []database.Feed
+	for _, feed := range m.data.feeds {
+		if !feed.DisabledAt.Valid {
+			feeds = append(feeds, feed)
+		}
+	}
+	slices.SortStableFunc(feeds, func(a, b database.Feed) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return feeds, nil
+}
+
+func (m *memStore) GetBrokenFeeds(ctx context.Context) ([]database.Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var feeds []database.Feed
+	for _, feed := range m.data.feeds {
+		if feed.ConsecutiveFailures > 0 || feed.DisabledAt.Valid {
+			feeds = append(feeds, feed)
+		}
+	}
+	slices.SortStableFunc(feeds, func(a, b database.Feed) int {
+		if c := compareNullTime(b.DisabledAt, a.DisabledAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ConsecutiveFailures, a.ConsecutiveFailures)
+	})
+	return feeds, nil
+}
+
+// compareNullTime orders times with NULL first, as NULLS FIRST does.
+func compareNullTime(a, b sql.NullTime) int {
+	switch {
+	case !a.Valid && !b.Valid:
+		return 0
+	case !a.Valid:
+		return -1
+	case !b.Valid:
+		return 1
+	}
+	return a.Time.Compare(b.Time)
+}
+
+func (m *memStore) EnableFeed(ctx context.Context, arg database.EnableFeedParams) (database.Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	feed := m.data.feedByURL(arg.Url)
+	if feed == nil {
+		return database.Feed{}, sql.ErrNoRows
+	}
+	feed.UpdatedAt = arg.UpdatedAt
+	feed.DisabledAt = sql.NullTime{}
+	feed.ConsecutiveFailures = 0
+	feed.NextFetchAt = sql.NullTime{}
+	return *feed, nil
+}
+
+func (m *memStore) ClaimNextFeedToFetch(ctx context.Context, arg database.ClaimNextFeedToFetchParams) (database.Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var next *database.Feed
+	for i := range m.data.feeds {
+		feed := &m.data.feeds[i]
+		if feed.DisabledAt.Valid || (feed.NextFetchAt.Valid && feed.NextFetchAt.Time.After(arg.UpdatedAt)) {
+			continue
+		}
+		if next == nil || compareNullTime(feed.NextFetchAt, next.NextFetchAt) < 0 {
+			next = feed
+		}
+	}
+	if next == nil {
+		return database.Feed{}, sql.ErrNoRows
+	}
+
+	next.UpdatedAt = arg.UpdatedAt
+	next.NextFetchAt = arg.NextFetchAt
+	return *next, nil
+}
+
+func (m *memStore) UpdateFeedCache(ctx context.Context, arg database.UpdateFeedCacheParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if feed := m.data.feed(arg.ID); feed != nil {
+		feed.UpdatedAt = arg.UpdatedAt
+		feed.Etag = arg.Etag
+		feed.LastModified = arg.LastModified
+	}
+	return nil
+}
+
+func (m *memStore) UpdateFeedSchedule(ctx context.Context, arg database.UpdateFeedScheduleParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if feed := m.data.feed(arg.ID); feed != nil {
+		feed.UpdatedAt = arg.UpdatedAt
+		feed.NextFetchAt = arg.NextFetchAt
+		feed.FetchInterval = arg.FetchInterval
+	}
+	return nil
+}
+
+func (m *memStore) MarkFeedFetched(ctx context.Context, arg database.MarkFeedFetchedParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if feed := m.data.feed(arg.ID); feed != nil {
+		feed.UpdatedAt = arg.UpdatedAt
+		feed.LastFetchedAt = sql.NullTime{Time: arg.UpdatedAt, Valid: true}
+	}
+	return nil
+}
+
+func (m *memStore) RecordFeedSuccess(ctx context.Context, arg database.RecordFeedSuccessParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if feed := m.data.feed(arg.ID); feed != nil {
+		feed.UpdatedAt = arg.UpdatedAt
+		feed.LastSuccessAt = sql.NullTime{Time: arg.UpdatedAt, Valid: true}
+		feed.ConsecutiveFailures = 0
+		feed.LastError = sql.NullString{}
+	}
+	return nil
+}
+
+func (m *memStore) RecordFeedFailure(ctx context.Context, arg database.RecordFeedFailureParams) (database.Feed, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	feed := m.data.feed(arg.ID)
+	if feed == nil {
+		return database.Feed{}, sql.ErrNoRows
+	}
+	feed.UpdatedAt = arg.UpdatedAt
+	feed.ConsecutiveFailures++
+	feed.LastError = arg.LastError
+	if feed.ConsecutiveFailures >= arg.MaxFailures {
+		feed.DisabledAt = sql.NullTime{Time: arg.UpdatedAt, Valid: true}
+	}
+	return *feed, nil
+}
+
+func (m *memStore) CreateFeedFetch(ctx context.Context, arg database.CreateFeedFetchParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.data.feed(arg.FeedID) == nil {
+		return fmt.Errorf("feed %s does not exist", arg.FeedID)
+	}
+
+	m.data.fetches = append(m.data.fetches, database.FeedFetch{
+		ID:            arg.ID,
+		FeedID:        arg.FeedID,
+		StartedAt:     arg.StartedAt,
+		FinishedAt:    arg.FinishedAt,
+		HttpStatus:    arg.HttpStatus,
+		Bytes:         arg.Bytes,
+		ItemsSeen:     arg.ItemsSeen,
+		PostsInserted: arg.PostsInserted,
+		Error:         arg.Error,
+		ItemsRejected: arg.ItemsRejected,
+	})
+	return nil
+}
+
+func (m *memStore) GetFeedHealth(ctx context.Context, startedAt time.Time) ([]database.GetFeedHealthRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows := make([]database.GetFeedHealthRow, 0, len(m.data.feeds))
+	for _, feed := range m.data.feeds {
+		row := database.GetFeedHealthRow{
+			ID:         feed.ID,
+			Name:       feed.Name,
+			Url:        feed.Url,
+			DisabledAt: feed.DisabledAt,
+		}
+
+		var latency time.Duration
+		for _, fetch := range m.data.fetches {
+			if fetch.FeedID != feed.ID || fetch.StartedAt.Before(startedAt) {
+				continue
+			}
+			row.Fetches++
+			latency += fetch.FinishedAt.Sub(fetch.StartedAt)
+			row.ItemsSeen += int64(fetch.ItemsSeen)
+			row.PostsInserted += int64(fetch.PostsInserted)
+			if !fetch.Error.Valid {
+				row.Successes++
+				if !row.LastSuccessAt.Valid || fetch.StartedAt.After(row.LastSuccessAt.Time) {
+					row.LastSuccessAt = sql.NullTime{Time: fetch.StartedAt, Valid: true}
+				}
+			}
+		}
+		if row.Fetches > 0 {
+			row.AvgLatencySeconds = latency.Seconds() / float64(row.Fetches)
+		}
+
+		for _, post := range m.data.posts {
+			if post.FeedID == feed.ID && (!row.LatestPostAt.Valid || post.PublishedAt.After(row.LatestPostAt.Time)) {
+				row.LatestPostAt = sql.NullTime{Time: post.PublishedAt, Valid: true}
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	slices.SortStableFunc(rows, func(a, b database.GetFeedHealthRow) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return rows, nil
+}
+
+func (m *memStore) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user := m.data.user(arg.UserID)
+	if user == nil {
+		return database.CreateFeedFollowRow{}, fmt.Errorf("user %s does not exist", arg.UserID)
+	}
+	feed := m.data.feed(arg.FeedID)
+	if feed == nil {
+		return database.CreateFeedFollowRow{}, fmt.Errorf("feed %s does not exist", arg.FeedID)
+	}
+	for _, follow := range m.data.follows {
+		if follow.UserID == arg.UserID && follow.FeedID == arg.FeedID {
+			return database.CreateFeedFollowRow{}, fmt.Errorf("user %s already follows feed %s", user.Name, feed.Url)
+		}
+	}
+
+	m.data.follows = append(m.data.follows, database.FeedFollow{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+	})
+	return database.CreateFeedFollowRow{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+		UserName:  user.Name,
+		FeedName:  feed.Name,
+	}, nil
+}
+
+func (m *memStore) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFeedFollowsForUserRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var rows []database.GetFeedFollowsForUserRow
+	for _, follow := range m.data.follows {
+		if follow.UserID != userID {
+			continue
+		}
+		row := database.GetFeedFollowsForUserRow{
+			ID:        follow.ID,
+			CreatedAt: follow.CreatedAt,
+			UpdatedAt: follow.UpdatedAt,
+			UserID:    follow.UserID,
+			FeedID:    follow.FeedID,
+			FeedName:  m.data.feed(follow.FeedID).Name,
+			UserName:  m.data.user(follow.UserID).Name,
+		}
+		for _, post := range m.data.posts {
+			if post.FeedID == follow.FeedID && !m.data.isRead(follow.UserID, post.ID) {
+				row.UnreadPosts++
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (m *memStore) DeleteFollow(ctx context.Context, arg database.DeleteFollowParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	feed := m.data.feedByURL(arg.Url)
+	if feed == nil {
+		return nil
+	}
+	m.data.follows = slices.DeleteFunc(m.data.follows, func(follow database.FeedFollow) bool {
+		return follow.UserID == arg.UserID && follow.FeedID == feed.ID
+	})
+	return nil
+}
+
+func (m *memStore) CreatePost(ctx context.Context, arg database.CreatePostParams) (database.CreatePostRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.data.feed(arg.FeedID) == nil {
+		return database.CreatePostRow{}, fmt.Errorf("feed %s does not exist", arg.FeedID)
+	}
+
+	for i := range m.data.posts {
+		post := &m.data.posts[i]
+		if post.FeedID != arg.FeedID || post.Guid != arg.Guid {
+			continue
+		}
+		if post.ContentHash == arg.ContentHash {
+			return database.CreatePostRow{}, sql.ErrNoRows
+		}
+		if post.ContentHash != "" {
+			post.RevisedAt = sql.NullTime{Time: arg.UpdatedAt, Valid: true}
+		}
+		post.Title = arg.Title
+		post.Url = arg.Url
+		post.Description = arg.Description
+		post.UpdatedAt = arg.UpdatedAt
+		post.ContentHash = arg.ContentHash
+		return postRow(*post, false), nil
+	}
+
+	post := database.Post{
+		ID:          arg.ID,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.UpdatedAt,
+		Title:       arg.Title,
+		Url:         arg.Url,
+		Description: arg.Description,
+		PublishedAt: arg.PublishedAt,
+		FeedID:      arg.FeedID,
+		Guid:        arg.Guid,
+		ContentHash: arg.ContentHash,
+	}
+	m.data.posts = append(m.data.posts, post)
+	return postRow(post, true), nil
+}
+
+func (m *memStore) CreatePosts(ctx context.Context, arg database.CreatePostsParams) ([]database.CreatePostsRow, error) {
+	var items []postBatchItem
+	if err := json.Unmarshal(arg.Items, &items); err != nil {
+		return nil, err
+	}
+
+	var rows []database.CreatePostsRow
+	for _, item := range items {
+		row, err := m.CreatePost(ctx,
+			database.CreatePostParams{
+				ID:          item.ID,
+				CreatedAt:   arg.CreatedAt,
+				UpdatedAt:   arg.CreatedAt,
+				Title:       item.Title,
+				Url:         item.Url,
+				Description: item.Description,
+				PublishedAt: item.PublishedAt,
+				FeedID:      arg.FeedID,
+				Guid:        item.Guid,
+				ContentHash: item.ContentHash,
+			})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, database.CreatePostsRow(row))
+	}
+	return rows, nil
+}
+
+func postRow(post database.Post, inserted bool) database.CreatePostRow {
+	return database.CreatePostRow{
+		ID:          post.ID,
+		CreatedAt:   post.CreatedAt,
+		UpdatedAt:   post.UpdatedAt,
+		Title:       post.Title,
+		Url:         post.Url,
+		Description: post.Description,
+		PublishedAt: post.PublishedAt,
+		FeedID:      post.FeedID,
+		Guid:        post.Guid,
+		ContentHash: post.ContentHash,
+		RevisedAt:   post.RevisedAt,
+		Inserted:    inserted,
+	}
+}
+
+func (m *memStore) GetPosts(ctx context.Context, arg database.GetPostsParams) ([]database.Post, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.data.latestPosts(arg.UserID, arg.Limit, false), nil
+}
+
+func (m *memStore) GetUnreadPosts(ctx context.Context, arg database.GetUnreadPostsParams) ([]database.Post, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.data.latestPosts(arg.UserID, arg.Limit, true), nil
+}
+
+func (d *memData) latestPosts(userID uuid.UUID, limit int32, unread bool) []database.Post {
+	var posts []database.Post
+	for _, post := range d.posts {
+		if d.isFollowing(userID, post.FeedID) && !(unread && d.isRead(userID, post.ID)) {
+			posts = append(posts, post)
+		}
+	}
+	slices.SortStableFunc(posts, func(a, b database.Post) int {
+		return b.PublishedAt.Compare(a.PublishedAt)
+	})
+	if len(posts) > int(limit) {
+		posts = posts[:limit]
+	}
+	return posts
+}
+
+func (m *memStore) MarkPostRead(ctx context.Context, arg database.MarkPostReadParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, post := range m.data.posts {
+		if post.ID != arg.PostID || !m.data.isFollowing(arg.UserID, post.FeedID) {
+			continue
+		}
+		if !m.data.isRead(arg.UserID, post.ID) {
+			m.data.reads = append(m.data.reads, database.PostRead{UserID: arg.UserID, PostID: post.ID, ReadAt: arg.ReadAt})
+		}
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m *memStore) MarkAllPostsRead(ctx context.Context, arg database.MarkAllPostsReadParams) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var marked int64
+	for _, post := range m.data.posts {
+		if !m.data.isFollowing(arg.UserID, post.FeedID) || m.data.isRead(arg.UserID, post.ID) {
+			continue
+		}
+		if arg.FeedUrl.Valid && m.data.feed(post.FeedID).Url != arg.FeedUrl.String {
+			continue
+		}
+		m.data.reads = append(m.data.reads, database.PostRead{UserID: arg.UserID, PostID: post.ID, ReadAt: arg.ReadAt})
+		marked++
+	}
+	return marked, nil
+}
+
+// SearchPosts matches the posts containing every word of the query, ignoring
+// the phrase, "or" and exclusion syntax supported by the databases.
+func (m *memStore) SearchPosts(ctx context.Context, arg database.SearchPostsParams) ([]database.SearchPostsRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	words := strings.FieldsFunc(strings.ToLower(arg.Query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	var rows []database.SearchPostsRow
+	for _, post := range m.data.posts {
+		feed := m.data.feed(post.FeedID)
+		if arg.FeedUrl.Valid && feed.Url != arg.FeedUrl.String {
+			continue
+		}
+		if arg.Since.Valid && post.PublishedAt.Before(arg.Since.Time) {
+			continue
+		}
+		if !m.data.isFollowing(arg.UserID, post.FeedID) {
+			continue
+		}
+
+		title, description := strings.ToLower(post.Title), strings.ToLower(post.Description)
+		rank := 0.0
+		for _, word := range words {
+			inTitle, inDescription := strings.Contains(title, word), strings.Contains(description, word)
+			if !inTitle && !inDescription {
+				rank = 0
+				break
+			}
+			if inTitle {
+				rank += 1
+			}
+			if inDescription {
+				rank += 0.4
+			}
+		}
+		if rank == 0 {
+			continue
+		}
+		age := max(arg.Now.Sub(post.PublishedAt).Seconds(), 0)
+
+		rows = append(rows, database.SearchPostsRow{
+			ID:          post.ID,
+			CreatedAt:   post.CreatedAt,
+			UpdatedAt:   post.UpdatedAt,
+			Title:       post.Title,
+			Url:         post.Url,
+			Description: post.Description,
+			PublishedAt: post.PublishedAt,
+			FeedID:      post.FeedID,
+			Guid:        post.Guid,
+			ContentHash: post.ContentHash,
+			RevisedAt:   post.RevisedAt,
+			FeedName:    feed.Name,
+			Rank:        rank / (1 + age/(30*24*60*60)),
+		})
+	}
+
+	slices.SortStableFunc(rows, func(a, b database.SearchPostsRow) int {
+		if c := cmp.Compare(b.Rank, a.Rank); c != 0 {
+			return c
+		}
+		return b.PublishedAt.Compare(a.PublishedAt)
+	})
+	if len(rows) > int(arg.Limit) {
+		rows = rows[:arg.Limit]
+	}
+	return rows, nil
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed sql/schema/*.sql sql/sqlite/schema/*.sql
+var schemaFS embed.FS
+
+// schemaDirs holds the migrations of each dialect. The SQLite migrations mirror
+// the PostgreSQL ones version by version.
+var schemaDirs = map[string]string{
+	dialectPostgres: "sql/schema",
+	dialectSQLite:   "sql/sqlite/schema",
+}
+
+// Applied versions are tracked in goose's own table, so databases migrated
+// with goose before gator could migrate itself are recognized.
+var createVersionTable = map[string]string{
+	dialectPostgres: `CREATE TABLE IF NOT EXISTS goose_db_version (
+    id SERIAL PRIMARY KEY,
+    version_id BIGINT NOT NULL,
+    is_applied BOOLEAN NOT NULL,
+    tstamp TIMESTAMP DEFAULT now()
+)`,
+	dialectSQLite: `CREATE TABLE IF NOT EXISTS goose_db_version (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version_id INTEGER NOT NULL,
+    is_applied INTEGER NOT NULL,
+    tstamp TIMESTAMP DEFAULT (datetime('now'))
+)`,
+}
+
+var versionTableExists = map[string]string{
+	dialectPostgres: "SELECT to_regclass('goose_db_version') IS NOT NULL",
+	dialectSQLite:   "SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'goose_db_version')",
+}
+
+type migration struct {
+	version int64
+	name    string
+	up      string
+	down    string
+}
+
+func loadMigrations(dialect string) ([]migration, error) {
+	files, err := fs.Glob(schemaFS, schemaDirs[dialect]+"/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(files))
+	for _, file := range files {
+		name := path.Base(file)
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s has no version prefix", name)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has an invalid version prefix: %w", name, err)
+		}
+
+		data, err := schemaFS.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		m := migration{version: version, name: name}
+		m.up, m.down, err = splitMigration(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", name, err)
+		}
+		migrations = append(migrations, m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+func splitMigration(data string) (string, string, error) {
+	_, rest, ok := strings.Cut(data, "-- +goose Up")
+	if !ok {
+		return "", "", fmt.Errorf("missing '-- +goose Up' annotation")
+	}
+	up, down, ok := strings.Cut(rest, "-- +goose Down")
+	if !ok {
+		return "", "", fmt.Errorf("missing '-- +goose Down' annotation")
+	}
+	return strings.TrimSpace(up), strings.TrimSpace(down), nil
+}
+
+func schemaVersion(ctx context.Context, db *sql.DB, dialect string) (int64, error) {
+	var exists bool
+	err := db.QueryRowContext(ctx, versionTableExists[dialect]).Scan(&exists)
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	var version sql.NullInt64
+	err = db.QueryRowContext(ctx,
+		"SELECT MAX(version_id) FROM goose_db_version WHERE is_applied").Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+
+	return version.Int64, nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, m migration, up bool) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if up {
+		if _, err = tx.ExecContext(ctx, m.up); err != nil {
+			return fmt.Errorf("error applying migration %s: %w", m.name, err)
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO goose_db_version (version_id, is_applied) VALUES ($1, true)", m.version)
+	} else {
+		if _, err = tx.ExecContext(ctx, m.down); err != nil {
+			return fmt.Errorf("error reverting migration %s: %w", m.name, err)
+		}
+		_, err = tx.ExecContext(ctx,
+			"DELETE FROM goose_db_version WHERE version_id = $1", m.version)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// checkSchema refuses to run against a database whose schema is older than the
+// migrations embedded in this binary.
+func checkSchema(ctx context.Context, db *sql.DB, dialect string) error {
+	migrations, err := loadMigrations(dialect)
+	if err != nil {
+		return err
+	}
+
+	version, err := schemaVersion(ctx, db, dialect)
+	if err != nil {
+		return fmt.Errorf("cannot read the database schema version: %w", err)
+	}
+
+	latest := migrations[len(migrations)-1].version
+	if version < latest {
+		return fmt.Errorf("the database schema is at version %d but gator requires version %d; run 'gator migrate up'", version, latest)
+	}
+
+	return nil
+}
+
+func handlerMigrate(ctx context.Context, s *state, cmd command) error {
+	if len(cmd.args) != 1 {
+		return fmt.Errorf("migrate command requires one argument (up, down or status); provided %v", len(cmd.args))
+	}
+
+	migrations, err := loadMigrations(s.dialect)
+	if err != nil {
+		return err
+	}
+
+	version, err := schemaVersion(ctx, s.conn, s.dialect)
+	if err != nil {
+		return err
+	}
+
+	switch cmd.args[0] {
+	case "up":
+		if _, err = s.conn.ExecContext(ctx, createVersionTable[s.dialect]); err != nil {
+			return err
+		}
+		applied := 0
+		for _, m := range migrations {
+			if m.version <= version {
+				continue
+			}
+			if err = applyMigration(ctx, s.conn, m, true); err != nil {
+				return err
+			}
+			fmt.Printf("Migration applied: %s\n", m.name)
+			applied++
+		}
+		if applied == 0 {
+			fmt.Printf("Database schema is up to date at version %d\n", version)
+		}
+	case "down":
+		for i := len(migrations) - 1; i >= 0; i-- {
+			if migrations[i].version != version {
+				continue
+			}
+			if err = applyMigration(ctx, s.conn, migrations[i], false); err != nil {
+				return err
+			}
+			fmt.Printf("Migration reverted: %s\n", migrations[i].name)
+			return nil
+		}
+		fmt.Println("No migration to revert")
+	case "status":
+		for _, m := range migrations {
+			status := "pending"
+			if m.version <= version {
+				status = "applied"
+			}
+			fmt.Printf("%-8s %s\n", status, m.name)
+		}
+		fmt.Printf("Database schema is at version %d\n", version)
+	default:
+		return fmt.Errorf("unknown migrate subcommand %s; use up, down or status", cmd.args[0])
+	}
+
+	return nil
+}
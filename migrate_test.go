@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"internal/rss"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// newSQLiteConn opens an empty in-memory SQLite database.
+func newSQLiteConn(t *testing.T) *sql.DB {
+	t.Helper()
+
+	conn, dialect, err := connect("sqlite::memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	if dialect != dialectSQLite {
+		t.Fatalf("got dialect %s, want %s", dialect, dialectSQLite)
+	}
+	return conn
+}
+
+// migrateSQLite applies the SQLite migrations up to version, or all of them when
+// version is 0.
+func migrateSQLite(t *testing.T, conn *sql.DB, version int64) {
+	t.Helper()
+
+	ctx := context.Background()
+	migrations, err := loadMigrations(dialectSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := schemaVersion(ctx, conn, dialectSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx, createVersionTable[dialectSQLite]); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current || (version != 0 && m.version > version) {
+			continue
+		}
+		if err := applyMigration(ctx, conn, m, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMigrateUpAndDown(t *testing.T) {
+	ctx := context.Background()
+	conn := newSQLiteConn(t)
+
+	migrateSQLite(t, conn, 0)
+	if err := checkSchema(ctx, conn, dialectSQLite); err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := loadMigrations(dialectSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if err := applyMigration(ctx, conn, migrations[i], false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	version, err := schemaVersion(ctx, conn, dialectSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0 {
+		t.Errorf("got version %d after reverting every migration, want 0", version)
+	}
+}
+
+func TestMigratePostKeys(t *testing.T) {
+	ctx := context.Background()
+	conn := newSQLiteConn(t)
+
+	// Posts stored before migration 007 were identified by their URL only.
+	migrateSQLite(t, conn, 6)
+
+	now := time.Now().UTC()
+	userID, feedID := uuid.NewString(), uuid.NewString()
+	if _, err := conn.ExecContext(ctx, "INSERT INTO users VALUES (?, ?, ?, 'alice')", userID, now, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx,
+		"INSERT INTO feeds (id, created_at, updated_at, name, url, user_id) VALUES (?, ?, ?, 'Blog', 'https://example.com/feed', ?)",
+		feedID, now, now, userID); err != nil {
+		t.Fatal(err)
+	}
+
+	items := []rss.Item{
+		{Link: "https://example.com/1", Title: "One", Description: "First"},
+		{Link: "https://example.com/2\n", Title: "Two", Description: "Second"},
+		{GUID: "https://example.com/3", Link: "https://example.com/3", Title: "Three", Description: "Third"},
+		{Title: "Four", Description: "Fourth"},
+	}
+	for _, item := range items {
+		if _, err := conn.ExecContext(ctx,
+			"INSERT INTO posts VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			uuid.NewString(), now, now, item.Title, item.Link, item.Description, now, feedID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	migrateSQLite(t, conn, 0)
+
+	for _, item := range items {
+		var guid string
+		err := conn.QueryRowContext(ctx, "SELECT guid FROM posts WHERE title = ?", item.Title).Scan(&guid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if guid != item.Key() {
+			t.Errorf("post '%s' has key %q, want %q", item.Title, guid, item.Key())
+		}
+	}
+}
@@ -0,0 +1,11 @@
+-- name: ClaimNextFeedToFetch :one
+UPDATE feeds
+SET updated_at = $1, next_fetch_at = $2
+WHERE feeds.id = (
+    SELECT id FROM feeds
+    WHERE disabled_at IS NULL AND (next_fetch_at IS NULL OR next_fetch_at <= $1)
+    ORDER BY next_fetch_at ASC NULLS FIRST
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
\ No newline at end of file
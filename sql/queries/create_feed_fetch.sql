@@ -0,0 +1,14 @@
+-- name: CreateFeedFetch :exec
+INSERT INTO feed_fetches (id, feed_id, started_at, finished_at, http_status, bytes, items_seen, posts_inserted, error, items_rejected)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10
+);
\ No newline at end of file
@@ -0,0 +1,13 @@
+-- name: CreatePosts :many
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content_hash)
+SELECT item.id, @created_at::timestamp, @created_at::timestamp, item.title, item.url, item.description, item.published_at, @feed_id::uuid, item.guid, item.content_hash
+FROM jsonb_to_recordset(@items::jsonb) AS item(id uuid, title text, url text, description text, published_at timestamp, guid text, content_hash text)
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = EXCLUDED.title,
+    url = EXCLUDED.url,
+    description = EXCLUDED.description,
+    updated_at = EXCLUDED.updated_at,
+    content_hash = EXCLUDED.content_hash,
+    revised_at = CASE WHEN posts.content_hash = '' THEN posts.revised_at ELSE EXCLUDED.updated_at END
+WHERE posts.content_hash <> EXCLUDED.content_hash
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content_hash, revised_at, (xmax = 0) AS inserted;
\ No newline at end of file
@@ -0,0 +1,31 @@
+-- name: GetFeedHealth :many
+-- Times are read from joined rows rather than computed with MAX, so that sqlc
+-- types them as nullable like the columns they come from.
+SELECT
+    feeds.id,
+    feeds.name,
+    feeds.url,
+    feeds.disabled_at,
+    COUNT(feed_fetches.id) AS fetches,
+    COUNT(feed_fetches.id) FILTER (WHERE feed_fetches.error IS NULL) AS successes,
+    COALESCE(AVG(EXTRACT(EPOCH FROM feed_fetches.finished_at - feed_fetches.started_at)), 0)::float8 AS avg_latency_seconds,
+    COALESCE(SUM(feed_fetches.items_seen), 0)::bigint AS items_seen,
+    COALESCE(SUM(feed_fetches.posts_inserted), 0)::bigint AS posts_inserted,
+    last_success.started_at AS last_success_at,
+    latest_post.published_at AS latest_post_at
+FROM feeds
+LEFT JOIN feed_fetches ON feed_fetches.feed_id = feeds.id AND feed_fetches.started_at >= $1
+LEFT JOIN feed_fetches AS last_success ON last_success.id = (
+    SELECT id FROM feed_fetches
+    WHERE feed_id = feeds.id AND started_at >= $1 AND error IS NULL
+    ORDER BY started_at DESC
+    LIMIT 1
+)
+LEFT JOIN posts AS latest_post ON latest_post.id = (
+    SELECT id FROM posts
+    WHERE feed_id = feeds.id
+    ORDER BY published_at DESC
+    LIMIT 1
+)
+GROUP BY feeds.id, last_success.id, latest_post.id
+ORDER BY feeds.name;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- name: GetBrokenFeeds :many
+SELECT * FROM feeds
+WHERE consecutive_failures > 0 OR disabled_at IS NOT NULL
+ORDER BY disabled_at DESC NULLS LAST, consecutive_failures DESC;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- name: GetUnreadPosts :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.guid, posts.content_hash, posts.revised_at
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $1
+WHERE NOT EXISTS (
+    SELECT 1 FROM post_reads WHERE post_reads.user_id = $1 AND post_reads.post_id = posts.id
+)
+ORDER BY published_at DESC
+LIMIT $2;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- name: MarkPostRead :execrows
+-- Only posts of followed feeds can be marked; marking a read post again keeps
+-- the time it was first read.
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, @read_at::timestamp
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = @user_id
+WHERE posts.id = @post_id
+ON CONFLICT (user_id, post_id) DO UPDATE SET read_at = post_reads.read_at;
\ No newline at end of file
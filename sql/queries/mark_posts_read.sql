@@ -0,0 +1,8 @@
+-- name: MarkAllPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, @read_at::timestamp
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = @user_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE (sqlc.narg('feed_url')::text IS NULL OR feeds.url = sqlc.narg('feed_url'))
+ON CONFLICT (user_id, post_id) DO NOTHING;
\ No newline at end of file
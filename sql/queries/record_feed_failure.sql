@@ -0,0 +1,8 @@
+-- name: RecordFeedFailure :one
+UPDATE feeds
+SET updated_at = @updated_at,
+    consecutive_failures = consecutive_failures + 1,
+    last_error = @last_error,
+    disabled_at = CASE WHEN consecutive_failures + 1 >= @max_failures::integer THEN @updated_at ELSE disabled_at END
+WHERE feeds.id = @id
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- name: SearchPosts :many
+-- The search vector is the expression of posts_search_idx, so that the index is
+-- used. Relevance is halved for posts published 30 days before now, and so on.
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.guid, posts.content_hash, posts.revised_at,
+    feeds.name AS feed_name,
+    (ts_rank(setweight(to_tsvector('english', posts.title), 'A') || setweight(to_tsvector('english', posts.description), 'B'),
+            websearch_to_tsquery('english', @query))
+        / (1 + GREATEST(EXTRACT(EPOCH FROM sqlc.arg('now')::timestamp - posts.published_at), 0) / 2592000))::float8 AS "rank"
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = @user_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE (setweight(to_tsvector('english', posts.title), 'A') || setweight(to_tsvector('english', posts.description), 'B'))
+    @@ websearch_to_tsquery('english', @query)
+AND (sqlc.narg('feed_url')::text IS NULL OR feeds.url = sqlc.narg('feed_url'))
+AND (sqlc.narg('since')::timestamp IS NULL OR posts.published_at >= sqlc.narg('since'))
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT sqlc.arg('limit');
\ No newline at end of file
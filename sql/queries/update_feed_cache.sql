@@ -0,0 +1,4 @@
+-- name: UpdateFeedCache :exec
+UPDATE feeds
+SET updated_at = $2, etag = $3, last_modified = $4
+WHERE feeds.id = $1;
\ No newline at end of file
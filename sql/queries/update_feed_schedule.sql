@@ -0,0 +1,4 @@
+-- name: UpdateFeedSchedule :exec
+UPDATE feeds
+SET updated_at = $2, next_fetch_at = $3, fetch_interval = $4
+WHERE feeds.id = $1;
\ No newline at end of file
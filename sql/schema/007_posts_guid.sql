@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN guid TEXT;
+-- Existing posts are keyed the way rss.Item.Key keys items without a GUID, or
+-- whose GUID is their link, so the next fetch matches them instead of storing
+-- them again. Items with any other GUID were not recorded and are stored once more.
+UPDATE posts SET guid = 'sha256:' || encode(sha256(convert_to(
+    CASE WHEN btrim(url, E' \t\n\r') = '' THEN title || E'\n' || description
+        ELSE btrim(url, E' \t\n\r') END, 'UTF8')), 'hex');
+ALTER TABLE posts ALTER COLUMN guid SET NOT NULL;
+ALTER TABLE posts DROP CONSTRAINT posts_url_key;
+ALTER TABLE posts ADD CONSTRAINT posts_feed_id_guid_key UNIQUE (feed_id, guid);
+
+-- +goose Down
+ALTER TABLE posts DROP CONSTRAINT posts_feed_id_guid_key;
+ALTER TABLE posts ADD CONSTRAINT posts_url_key UNIQUE (url);
+ALTER TABLE posts DROP COLUMN guid;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE feed_fetches ADD COLUMN items_rejected INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE feed_fetches DROP COLUMN items_rejected;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- +goose Up
+-- The search vector is not stored; SearchPosts repeats this expression, so that
+-- PostgreSQL matches it with the index.
+CREATE INDEX posts_search_idx ON posts USING GIN (
+    (setweight(to_tsvector('english', title), 'A') || setweight(to_tsvector('english', description), 'B'))
+);
+
+-- +goose Down
+DROP INDEX posts_search_idx;
\ No newline at end of file
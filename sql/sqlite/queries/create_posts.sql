@@ -0,0 +1,17 @@
+-- name: CreatePosts :many
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content_hash)
+SELECT json_extract(item.value, '$.id'), ?1, ?1, json_extract(item.value, '$.title'), json_extract(item.value, '$.url'),
+    json_extract(item.value, '$.description'), json_extract(item.value, '$.published_at'), ?2,
+    json_extract(item.value, '$.guid'), json_extract(item.value, '$.content_hash')
+FROM json_each(?3) AS item
+-- The WHERE clause keeps SQLite from reading the ON of the upsert as a join constraint.
+WHERE true
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = EXCLUDED.title,
+    url = EXCLUDED.url,
+    description = EXCLUDED.description,
+    updated_at = EXCLUDED.updated_at,
+    content_hash = EXCLUDED.content_hash,
+    revised_at = CASE WHEN posts.content_hash = '' THEN posts.revised_at ELSE EXCLUDED.updated_at END
+WHERE posts.content_hash <> EXCLUDED.content_hash
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content_hash, revised_at, (created_at = ?1) AS inserted;
\ No newline at end of file
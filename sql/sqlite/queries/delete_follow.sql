@@ -0,0 +1,4 @@
+-- name: DeleteFollow :exec
+DELETE FROM feed_follows
+WHERE feed_follows.user_id = ?1
+AND feed_follows.feed_id IN (SELECT feeds.id FROM feeds WHERE feeds.url = ?2);
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- name: EnableFeed :one
+UPDATE feeds
+SET updated_at = ?2, disabled_at = NULL, consecutive_failures = 0, next_fetch_at = NULL
+WHERE feeds.url = ?1
+RETURNING *;
\ No newline at end of file
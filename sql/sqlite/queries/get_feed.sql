@@ -0,0 +1,2 @@
+-- name: GetFeed :one
+SELECT * FROM feeds WHERE url = ?1 LIMIT 1;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- name: GetEnabledFeeds :many
+SELECT * FROM feeds
+WHERE disabled_at IS NULL
+ORDER BY name;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- name: GetPosts :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.guid, posts.content_hash, posts.revised_at
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = ?1
+ORDER BY published_at DESC
+LIMIT ?2;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- name: GetUser :one
+SELECT * FROM users WHERE name = ?1 LIMIT 1;
\ No newline at end of file
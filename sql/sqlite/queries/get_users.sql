@@ -0,0 +1,2 @@
+-- name: GetUsers :many
+SELECT * FROM users;
\ No newline at end of file
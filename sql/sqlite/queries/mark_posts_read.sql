@@ -0,0 +1,8 @@
+-- name: MarkAllPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, ?1
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = ?2
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE (?3 IS NULL OR feeds.url = ?3)
+ON CONFLICT (user_id, post_id) DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- name: RecordFeedFailure :one
+UPDATE feeds
+SET updated_at = ?1,
+    consecutive_failures = consecutive_failures + 1,
+    last_error = ?2,
+    disabled_at = CASE WHEN consecutive_failures + 1 >= CAST(?3 AS INTEGER) THEN ?1 ELSE disabled_at END
+WHERE feeds.id = ?4
+RETURNING *;
\ No newline at end of file
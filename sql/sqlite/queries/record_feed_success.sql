@@ -0,0 +1,4 @@
+-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET updated_at = ?2, last_success_at = ?2, consecutive_failures = 0, last_error = NULL
+WHERE feeds.id = ?1;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- name: ResetUsers :exec
+DELETE FROM users;
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- name: SearchPosts :many
+-- The query is in FTS5 syntax, converted from the web search syntax accepted by
+-- PostgreSQL. Relevance is halved for posts published 30 days before now, and so on.
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.guid, posts.content_hash, posts.revised_at,
+    feeds.name AS feed_name,
+    CAST(-bm25(posts_search, 2.5, 1.0)
+        / (1 + MAX((julianday(?2) - julianday(posts.published_at)) * 86400, 0) / 2592000) AS REAL) AS "rank"
+FROM posts_search
+INNER JOIN posts_search_rowids ON posts_search_rowids.rowid = posts_search.rowid
+INNER JOIN posts ON posts.id = posts_search_rowids.post_id
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = ?3
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE posts_search MATCH ?1
+AND (?4 IS NULL OR feeds.url = ?4)
+AND (?5 IS NULL OR posts.published_at >= ?5)
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT ?6;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE posts (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL,
+    published_at TIMESTAMP NOT NULL,
+    feed_id TEXT NOT NULL REFERENCES feeds (id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE posts;
\ No newline at end of file
@@ -0,0 +1,40 @@
+-- +goose Up
+-- SQLite cannot alter constraints, so the table is rebuilt.
+CREATE TABLE posts_new (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL,
+    description TEXT NOT NULL,
+    published_at TIMESTAMP NOT NULL,
+    feed_id TEXT NOT NULL REFERENCES feeds (id) ON DELETE CASCADE,
+    guid TEXT NOT NULL,
+    UNIQUE (feed_id, guid)
+);
+-- Existing posts are keyed the way rss.Item.Key keys items without a GUID, or
+-- whose GUID is their link, so the next fetch matches them instead of storing
+-- them again. Items with any other GUID were not recorded and are stored once more.
+-- sha256 is registered by gator, as SQLite has no hash functions.
+INSERT INTO posts_new SELECT id, created_at, updated_at, title, url, description, published_at, feed_id,
+    'sha256:' || lower(hex(sha256(
+        CASE WHEN trim(url, ' ' || char(9, 10, 13)) = '' THEN title || char(10) || description
+            ELSE trim(url, ' ' || char(9, 10, 13)) END)))
+FROM posts;
+DROP TABLE posts;
+ALTER TABLE posts_new RENAME TO posts;
+
+-- +goose Down
+CREATE TABLE posts_old (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL,
+    published_at TIMESTAMP NOT NULL,
+    feed_id TEXT NOT NULL REFERENCES feeds (id) ON DELETE CASCADE
+);
+INSERT INTO posts_old SELECT id, created_at, updated_at, title, url, description, published_at, feed_id FROM posts;
+DROP TABLE posts;
+ALTER TABLE posts_old RENAME TO posts;
\ No newline at end of file
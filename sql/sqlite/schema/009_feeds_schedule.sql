@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN next_fetch_at TIMESTAMP;
+ALTER TABLE feeds ADD COLUMN fetch_interval INTEGER;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN fetch_interval;
+ALTER TABLE feeds DROP COLUMN next_fetch_at;
\ No newline at end of file
@@ -0,0 +1,42 @@
+-- +goose Up
+-- SQLite has no tsvector; posts are indexed by an FTS5 table instead. Its rows
+-- are keyed by posts_search_rowids, since the implicit rowids of posts may change.
+CREATE TABLE posts_search_rowids (
+    rowid INTEGER PRIMARY KEY,
+    post_id TEXT NOT NULL UNIQUE REFERENCES posts (id) ON DELETE CASCADE
+);
+
+CREATE VIRTUAL TABLE posts_search USING fts5 (title, description, tokenize = 'porter unicode61');
+
+INSERT INTO posts_search_rowids (post_id) SELECT id FROM posts;
+INSERT INTO posts_search (rowid, title, description)
+SELECT posts_search_rowids.rowid, posts.title, posts.description
+FROM posts_search_rowids
+INNER JOIN posts ON posts.id = posts_search_rowids.post_id;
+
+-- +goose StatementBegin
+CREATE TRIGGER posts_search_insert AFTER INSERT ON posts BEGIN
+    INSERT INTO posts_search_rowids (post_id) VALUES (new.id);
+    INSERT INTO posts_search (rowid, title, description) VALUES (last_insert_rowid(), new.title, new.description);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER posts_search_update AFTER UPDATE OF title, description ON posts BEGIN
+    UPDATE posts_search SET title = new.title, description = new.description
+    WHERE rowid = (SELECT rowid FROM posts_search_rowids WHERE post_id = new.id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER posts_search_delete BEFORE DELETE ON posts BEGIN
+    DELETE FROM posts_search WHERE rowid = (SELECT rowid FROM posts_search_rowids WHERE post_id = old.id);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER posts_search_delete;
+DROP TRIGGER posts_search_update;
+DROP TRIGGER posts_search_insert;
+DROP TABLE posts_search;
+DROP TABLE posts_search_rowids;
\ No newline at end of file
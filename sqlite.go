@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"internal/database"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+const (
+	dialectPostgres = "postgres"
+	dialectSQLite   = "sqlite"
+)
+
+// sqliteTimeFormat is the format in which the driver writes times with _time_format=sqlite.
+const sqliteTimeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+// The SQLite connection enforces foreign keys like PostgreSQL does, waits for
+// locks held by other gator processes and stores times in a format that sorts
+// as text.
+const sqlitePragmas = "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite"
+
+func init() {
+	// Migrations hash existing rows with sha256, which PostgreSQL has built in.
+	sqlite.MustRegisterDeterministicScalarFunction("sha256", 1, sqliteSHA256)
+}
+
+func sqliteSHA256(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	var data []byte
+	switch v := args[0].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		data = fmt.Append(nil, v)
+	}
+
+	sum := sha256.Sum256(data)
+	return sum[:], nil
+}
+
+//go:embed sql/sqlite/queries/*.sql
+var sqliteQueryFS embed.FS
+
+// sqliteQueries maps each query name to its SQLite version. The SQLite query
+// set mirrors sql/queries, with the same names, parameters and columns.
+var sqliteQueries = mustLoadSQLiteQueries()
+
+func mustLoadSQLiteQueries() map[string]string {
+	queries, err := loadSQLiteQueries()
+	if err != nil {
+		panic(err)
+	}
+	return queries
+}
+
+func loadSQLiteQueries() (map[string]string, error) {
+	files, err := fs.Glob(sqliteQueryFS, "sql/sqlite/queries/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make(map[string]string)
+	for _, file := range files {
+		data, err := sqliteQueryFS.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		name := queryName(string(data))
+		if name == "" {
+			return nil, fmt.Errorf("query file %s has no '-- name:' annotation", file)
+		}
+		queries[name] = string(data)
+	}
+
+	return queries, nil
+}
+
+// queryName returns the name in the sqlc annotation that starts a query.
+func queryName(query string) string {
+	line, _, _ := strings.Cut(query, "\n")
+	rest, ok := strings.CutPrefix(line, "-- name: ")
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	return name
+}
+
+// parseDatabaseURL returns the SQL dialect of a database URL and the data source
+// name for its driver. URLs starting with sqlite: name a local database file, as
+// in sqlite:///home/user/gator.db or sqlite:gator.db; anything else is passed to
+// the PostgreSQL driver.
+func parseDatabaseURL(dbURL string) (string, string, error) {
+	path, ok := strings.CutPrefix(dbURL, "sqlite:")
+	if !ok {
+		return dialectPostgres, dbURL, nil
+	}
+
+	path = strings.TrimPrefix(path, "//")
+	path, _, _ = strings.Cut(path, "?")
+	if path == "" {
+		return "", "", fmt.Errorf("the SQLite database URL %s has no file path", dbURL)
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", err
+		}
+		path = filepath.Join(home, rest)
+	}
+
+	return dialectSQLite, path + "?" + sqlitePragmas, nil
+}
+
+// sqliteDB runs the queries generated by sqlc for PostgreSQL against SQLite, by
+// replacing each query with its version from the SQLite query set.
+type sqliteDB struct {
+	db database.DBTX
+}
+
+// sqliteQuery returns the name and the SQLite version of a generated query. Every
+// query must have a version, so a missing one is a bug in the SQLite query set.
+func sqliteQuery(query string) (string, string) {
+	name := queryName(query)
+	sqliteQuery, ok := sqliteQueries[name]
+	if !ok {
+		panic(fmt.Sprintf("query %s has no SQLite version", name))
+	}
+	return name, sqliteQuery
+}
+
+// query returns the SQLite version of a generated query and its arguments.
+func (d sqliteDB) query(query string, args []interface{}) (string, []interface{}) {
+	name, query := sqliteQuery(query)
+
+	args = sqliteArgs(args)
+	if convert, ok := sqliteQueryArgs[name]; ok {
+		convert(args)
+	}
+
+	return query, args
+}
+
+func (d sqliteDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, args = d.query(query, args)
+	return d.db.ExecContext(ctx, query, args...)
+}
+
+// PrepareContext prepares the SQLite version of a query. Arguments passed to the
+// statement later are not converted, so the queries listed in sqliteQueryArgs
+// cannot run prepared.
+func (d sqliteDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	_, query = sqliteQuery(query)
+	return d.db.PrepareContext(ctx, query)
+}
+
+func (d sqliteDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args = d.query(query, args)
+	return d.db.QueryContext(ctx, query, args...)
+}
+
+func (d sqliteDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query, args = d.query(query, args)
+	return d.db.QueryRowContext(ctx, query, args...)
+}
+
+// sqliteQueryArgs converts the arguments of the queries whose parameters are
+// written differently in SQLite.
+var sqliteQueryArgs = map[string]func(args []interface{}){
+	"SearchPosts": func(args []interface{}) {
+		args[0] = ftsQuery(args[0].(string))
+	},
+	"CreatePosts": func(args []interface{}) {
+		args[2] = sqliteJSONTimes(args[2].(json.RawMessage), "published_at")
+	},
+}
+
+// sqliteJSONTimes rewrites the times of the given fields in a JSON array of objects
+// the way the driver writes time arguments, in UTC, and returns the array as text
+// for the JSON functions of SQLite, which read blobs as binary JSON.
+func sqliteJSONTimes(data json.RawMessage, fields ...string) string {
+	var objects []map[string]interface{}
+	if err := json.Unmarshal(data, &objects); err != nil {
+		return string(data)
+	}
+
+	for _, object := range objects {
+		for _, field := range fields {
+			value, _ := object[field].(string)
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				object[field] = t.UTC().Format(sqliteTimeFormat)
+			}
+		}
+	}
+
+	converted, err := json.Marshal(objects)
+	if err != nil {
+		return string(data)
+	}
+	return string(converted)
+}
+
+// sqliteArgs converts times to UTC. SQLite stores times as text, which only
+// compares correctly when every time has the same offset.
+func sqliteArgs(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			converted[i] = v.UTC()
+		case sql.NullTime:
+			converted[i] = sql.NullTime{Time: v.Time.UTC(), Valid: v.Valid}
+		default:
+			converted[i] = arg
+		}
+	}
+	return converted
+}
+
+type searchTerm struct {
+	text    string
+	negated bool
+	quoted  bool
+}
+
+// ftsQuery converts a query in the web search syntax of PostgreSQL, made of words,
+// quoted phrases, "or" and -excluded terms, to the FTS5 query syntax. Every term
+// is quoted, so punctuation in the query cannot make it invalid.
+func ftsQuery(query string) string {
+	var groups, included, excluded []string
+	endGroup := func() {
+		// FTS5 cannot match only excluded terms, so they need an included one.
+		if len(included) > 0 {
+			group := strings.Join(included, " ")
+			for _, term := range excluded {
+				group += " NOT " + term
+			}
+			groups = append(groups, "("+group+")")
+		}
+		included, excluded = nil, nil
+	}
+
+	for _, term := range searchTerms(query) {
+		quoted := `"` + strings.ReplaceAll(term.text, `"`, `""`) + `"`
+		switch {
+		case !term.quoted && !term.negated && strings.EqualFold(term.text, "or"):
+			endGroup()
+		case term.negated:
+			excluded = append(excluded, quoted)
+		default:
+			included = append(included, quoted)
+		}
+	}
+	endGroup()
+
+	if len(groups) == 0 {
+		// An empty phrase matches no post.
+		return `""`
+	}
+	return strings.Join(groups, " OR ")
+}
+
+func searchTerms(query string) []searchTerm {
+	var terms []searchTerm
+	for query != "" {
+		var term searchTerm
+		query = strings.TrimLeft(query, " \t\n")
+		if rest, ok := strings.CutPrefix(query, "-"); ok {
+			term.negated = true
+			query = rest
+		}
+
+		if rest, ok := strings.CutPrefix(query, `"`); ok {
+			term.quoted = true
+			term.text, query, _ = strings.Cut(rest, `"`)
+		} else {
+			end := strings.IndexAny(query, " \t\n\"")
+			if end < 0 {
+				end = len(query)
+			}
+			term.text, query = query[:end], query[end:]
+		}
+
+		if strings.TrimSpace(term.text) != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
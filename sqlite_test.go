@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"internal/database"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// newSQLiteStore returns a store on an in-memory SQLite database with every
+// migration applied.
+func newSQLiteStore(t *testing.T) *sqlStore {
+	t.Helper()
+
+	conn := newSQLiteConn(t)
+	migrateSQLite(t, conn, 0)
+	return newSQLStore(conn, dialectSQLite)
+}
+
+var (
+	postgresParam = regexp.MustCompile(`\$\d+|@(\w+)|sqlc\.n?arg\('(\w+)'\)`)
+	sqliteParam   = regexp.MustCompile(`\?\d+`)
+)
+
+// Every query needs a SQLite version taking as many parameters, or the store
+// panics when running it.
+func TestSQLiteQueriesMatch(t *testing.T) {
+	files, err := filepath.Glob("sql/queries/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make(map[string]bool)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := queryName(string(data))
+		names[name] = true
+
+		sqliteQuery, ok := sqliteQueries[name]
+		if !ok {
+			t.Errorf("query %s of %s has no SQLite version", name, file)
+			continue
+		}
+
+		// Named parameters appear as @name or sqlc.arg('name').
+		var params []string
+		for _, match := range postgresParam.FindAllStringSubmatch(string(data), -1) {
+			if name := match[1] + match[2]; name != "" {
+				params = append(params, "@"+name)
+			} else {
+				params = append(params, match[0])
+			}
+		}
+		want := distinct(params)
+		if got := distinct(sqliteParam.FindAllString(sqliteQuery, -1)); got != want {
+			t.Errorf("SQLite version of query %s has %d parameters, want %d", name, got, want)
+		}
+	}
+
+	for name := range sqliteQueries {
+		if !names[name] {
+			t.Errorf("SQLite query %s has no PostgreSQL version", name)
+		}
+	}
+}
+
+func TestSQLitePrepare(t *testing.T) {
+	db := sqliteDB{newSQLiteStore(t).conn}
+	for name := range sqliteQueries {
+		stmt, err := db.PrepareContext(context.Background(), "-- name: "+name+" :exec\n")
+		if err != nil {
+			t.Errorf("preparing %s: %v", name, err)
+			continue
+		}
+		stmt.Close()
+	}
+}
+
+func distinct(values []string) int {
+	seen := make(map[string]bool)
+	for _, value := range values {
+		seen[value] = true
+	}
+	return len(seen)
+}
+
+// TestSQLiteStore runs every method of the store against SQLite, so the SQLite
+// query set cannot drift from the generated code unnoticed.
+func TestSQLiteStore(t *testing.T) {
+	ctx := context.Background()
+	st := newSQLiteStore(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	covered := make(map[string]bool)
+	check := func(method string, err error) {
+		t.Helper()
+		covered[method] = true
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+	}
+
+	// Users
+	user, err := st.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "alice"})
+	check("CreateUser", err)
+	got, err := st.GetUser(ctx, "alice")
+	check("GetUser", err)
+	if got.ID != user.ID || !got.CreatedAt.Equal(now) {
+		t.Errorf("GetUser = %+v, want %+v", got, user)
+	}
+	users, err := st.GetUsers(ctx)
+	check("GetUsers", err)
+	if len(users) != 1 {
+		t.Errorf("GetUsers returned %d users, want 1", len(users))
+	}
+
+	// Feeds
+	feed, err := st.CreateFeed(ctx, database.CreateFeedParams{
+		ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "Blog", Url: "https://example.com/feed", UserID: user.ID,
+	})
+	check("CreateFeed", err)
+	gotFeed, err := st.GetFeed(ctx, feed.Url)
+	check("GetFeed", err)
+	if gotFeed.ID != feed.ID || gotFeed.NextFetchAt.Valid {
+		t.Errorf("GetFeed = %+v, want %+v", gotFeed, feed)
+	}
+	feeds, err := st.GetFeeds(ctx)
+	check("GetFeeds", err)
+	if len(feeds) != 1 || feeds[0].UserName.String != "alice" {
+		t.Errorf("GetFeeds = %+v", feeds)
+	}
+
+	claimed, err := st.ClaimNextFeedToFetch(ctx, database.ClaimNextFeedToFetchParams{
+		UpdatedAt: now, NextFetchAt: sql.NullTime{Time: now.Add(time.Hour), Valid: true},
+	})
+	check("ClaimNextFeedToFetch", err)
+	if claimed.ID != feed.ID || !claimed.NextFetchAt.Time.Equal(now.Add(time.Hour)) {
+		t.Errorf("ClaimNextFeedToFetch = %+v", claimed)
+	}
+	if _, err := st.ClaimNextFeedToFetch(ctx, database.ClaimNextFeedToFetchParams{UpdatedAt: now}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("claiming a feed that is not due returned %v, want %v", err, sql.ErrNoRows)
+	}
+
+	check("UpdateFeedCache", st.UpdateFeedCache(ctx, database.UpdateFeedCacheParams{
+		ID: feed.ID, UpdatedAt: now, Etag: sql.NullString{String: `"v1"`, Valid: true},
+	}))
+	check("MarkFeedFetched", st.MarkFeedFetched(ctx, database.MarkFeedFetchedParams{ID: feed.ID, UpdatedAt: now}))
+	check("UpdateFeedSchedule", st.UpdateFeedSchedule(ctx, database.UpdateFeedScheduleParams{
+		ID: feed.ID, UpdatedAt: now, NextFetchAt: sql.NullTime{Time: now.Add(-time.Minute), Valid: true},
+		FetchInterval: sql.NullInt32{Int32: 600, Valid: true},
+	}))
+	check("RecordFeedSuccess", st.RecordFeedSuccess(ctx, database.RecordFeedSuccessParams{ID: feed.ID, UpdatedAt: now}))
+	gotFeed, err = st.GetFeed(ctx, feed.Url)
+	check("GetFeed", err)
+	if gotFeed.Etag.String != `"v1"` || !gotFeed.LastFetchedAt.Valid || !gotFeed.LastSuccessAt.Valid ||
+		gotFeed.FetchInterval.Int32 != 600 || !gotFeed.NextFetchAt.Time.Equal(now.Add(-time.Minute)) {
+		t.Errorf("feed after fetching = %+v", gotFeed)
+	}
+
+	enabled, err := st.GetEnabledFeeds(ctx)
+	check("GetEnabledFeeds", err)
+	if len(enabled) != 1 {
+		t.Errorf("GetEnabledFeeds returned %d feeds, want 1", len(enabled))
+	}
+	failed, err := st.RecordFeedFailure(ctx, database.RecordFeedFailureParams{
+		UpdatedAt: now, LastError: sql.NullString{String: "boom", Valid: true}, MaxFailures: 1, ID: feed.ID,
+	})
+	check("RecordFeedFailure", err)
+	if failed.ConsecutiveFailures != 1 || !failed.DisabledAt.Valid {
+		t.Errorf("RecordFeedFailure = %+v, want a disabled feed", failed)
+	}
+	broken, err := st.GetBrokenFeeds(ctx)
+	check("GetBrokenFeeds", err)
+	if len(broken) != 1 || broken[0].LastError.String != "boom" {
+		t.Errorf("GetBrokenFeeds = %+v", broken)
+	}
+	reenabled, err := st.EnableFeed(ctx, database.EnableFeedParams{Url: feed.Url, UpdatedAt: now})
+	check("EnableFeed", err)
+	if reenabled.DisabledAt.Valid || reenabled.ConsecutiveFailures != 0 {
+		t.Errorf("EnableFeed = %+v", reenabled)
+	}
+
+	check("CreateFeedFetch", st.CreateFeedFetch(ctx, database.CreateFeedFetchParams{
+		ID: uuid.New(), FeedID: feed.ID, StartedAt: now, FinishedAt: now.Add(500 * time.Millisecond),
+		HttpStatus: sql.NullInt32{Int32: 200, Valid: true}, Bytes: 100, ItemsSeen: 3, PostsInserted: 2,
+	}))
+
+	// Follows
+	follow, err := st.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID: uuid.New(), CreatedAt: now, UpdatedAt: now, UserID: user.ID, FeedID: feed.ID,
+	})
+	check("CreateFeedFollow", err)
+	if follow.UserName != "alice" || follow.FeedName != "Blog" {
+		t.Errorf("CreateFeedFollow = %+v", follow)
+	}
+
+	// Posts
+	published := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", 2*60*60))
+	post, err := st.CreatePost(ctx, database.CreatePostParams{
+		ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Title: "Go generics", Url: "https://example.com/1",
+		Description: "Type parameters", PublishedAt: published, FeedID: feed.ID, Guid: "1", ContentHash: "a",
+	})
+	check("CreatePost", err)
+	if !post.Inserted || !post.PublishedAt.Equal(published) {
+		t.Errorf("CreatePost = %+v", post)
+	}
+
+	items, err := json.Marshal([]postBatchItem{
+		{ID: uuid.New(), Title: "Go generics, revised", Url: "https://example.com/1", PublishedAt: published, Guid: "1", ContentHash: "b"},
+		{ID: uuid.New(), Title: "Rust traits", Url: "https://example.com/2", PublishedAt: published.Add(time.Hour), Guid: "2", ContentHash: "c"},
+		{ID: uuid.New(), Title: "Unchanged", Url: "https://example.com/3", PublishedAt: published, Guid: "3", ContentHash: "d"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = st.CreatePosts(ctx, database.CreatePostsParams{CreatedAt: now.Add(-time.Second), FeedID: feed.ID, Items: items})
+	check("CreatePosts", err)
+	rows, err := st.CreatePosts(ctx, database.CreatePostsParams{CreatedAt: now.Add(time.Second), FeedID: feed.ID, Items: items})
+	check("CreatePosts", err)
+	if len(rows) != 0 {
+		t.Errorf("storing the same posts again returned %+v, want nothing", rows)
+	}
+
+	posts, err := st.GetPosts(ctx, database.GetPostsParams{UserID: user.ID, Limit: 10})
+	check("GetPosts", err)
+	if len(posts) != 3 || posts[0].Title != "Rust traits" || !posts[0].PublishedAt.Equal(published.Add(time.Hour)) {
+		t.Fatalf("GetPosts = %+v", posts)
+	}
+	for _, p := range posts {
+		if p.Guid == "1" && (p.Title != "Go generics, revised" || !p.RevisedAt.Valid) {
+			t.Errorf("post 1 was not revised: %+v", p)
+		}
+	}
+
+	results, err := st.SearchPosts(ctx, database.SearchPostsParams{
+		Query: "generics -rust", Now: now, UserID: user.ID, Limit: 10,
+	})
+	check("SearchPosts", err)
+	if len(results) != 1 || results[0].Guid != "1" || results[0].FeedName != "Blog" || results[0].Rank <= 0 {
+		t.Errorf("SearchPosts = %+v", results)
+	}
+
+	marked, err := st.MarkPostRead(ctx, database.MarkPostReadParams{ReadAt: now, UserID: user.ID, PostID: posts[0].ID})
+	check("MarkPostRead", err)
+	if marked != 1 {
+		t.Errorf("MarkPostRead marked %d posts, want 1", marked)
+	}
+	unread, err := st.GetUnreadPosts(ctx, database.GetUnreadPostsParams{UserID: user.ID, Limit: 10})
+	check("GetUnreadPosts", err)
+	if len(unread) != 2 {
+		t.Errorf("GetUnreadPosts returned %d posts, want 2", len(unread))
+	}
+	follows, err := st.GetFeedFollowsForUser(ctx, user.ID)
+	check("GetFeedFollowsForUser", err)
+	if len(follows) != 1 || follows[0].UnreadPosts != 2 || follows[0].FeedName != "Blog" {
+		t.Errorf("GetFeedFollowsForUser = %+v", follows)
+	}
+	marked, err = st.MarkAllPostsRead(ctx, database.MarkAllPostsReadParams{
+		ReadAt: now, UserID: user.ID, FeedUrl: sql.NullString{String: feed.Url, Valid: true},
+	})
+	check("MarkAllPostsRead", err)
+	if marked != 2 {
+		t.Errorf("MarkAllPostsRead marked %d posts, want 2", marked)
+	}
+
+	health, err := st.GetFeedHealth(ctx, now.Add(-time.Hour))
+	check("GetFeedHealth", err)
+	if len(health) != 1 || health[0].Fetches != 1 || health[0].Successes != 1 || health[0].PostsInserted != 2 ||
+		!health[0].LatestPostAt.Valid {
+		t.Errorf("GetFeedHealth = %+v", health)
+	}
+
+	// Transactions
+	errRollback := errors.New("rollback")
+	err = st.InTx(ctx, func(tx Store) error {
+		check("Savepoint", tx.Savepoint(ctx, func() error {
+			return tx.DeleteFollow(ctx, database.DeleteFollowParams{UserID: user.ID, Url: feed.Url})
+		}))
+		err := tx.Savepoint(ctx, func() error {
+			if err := tx.ResetUsers(ctx); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Errorf("Savepoint returned %v, want %v", err, errRollback)
+		}
+		return errRollback
+	})
+	covered["InTx"] = true
+	if !errors.Is(err, errRollback) {
+		t.Errorf("InTx returned %v, want %v", err, errRollback)
+	}
+	if follows, err := st.GetFeedFollowsForUser(ctx, user.ID); err != nil || len(follows) != 1 {
+		t.Errorf("a rolled back transaction deleted the follow: %+v, %v", follows, err)
+	}
+
+	check("InTx", st.InTx(ctx, func(tx Store) error {
+		check("DeleteFollow", tx.DeleteFollow(ctx, database.DeleteFollowParams{UserID: user.ID, Url: feed.Url}))
+		return nil
+	}))
+	if follows, err := st.GetFeedFollowsForUser(ctx, user.ID); err != nil || len(follows) != 0 {
+		t.Errorf("a committed transaction did not delete the follow: %+v, %v", follows, err)
+	}
+
+	check("ResetUsers", st.ResetUsers(ctx))
+	if feeds, err := st.GetFeeds(ctx); err != nil || len(feeds) != 0 {
+		t.Errorf("resetting users left feeds %+v, %v", feeds, err)
+	}
+
+	store := reflect.TypeOf((*Store)(nil)).Elem()
+	for i := 0; i < store.NumMethod(); i++ {
+		if name := store.Method(i).Name; !covered[name] {
+			t.Errorf("Store method %s is not run against SQLite", name)
+		}
+	}
+}
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []searchTerm
+	}{
+		{"", nil},
+		{"  go\tgenerics\n", []searchTerm{{text: "go"}, {text: "generics"}}},
+		{`"type parameters" -rust`, []searchTerm{{text: "type parameters", quoted: true}, {text: "rust", negated: true}}},
+		{`-"borrow checker"`, []searchTerm{{text: "borrow checker", negated: true, quoted: true}}},
+		{`go"lang`, []searchTerm{{text: "go"}, {text: "lang", quoted: true}}},
+		{`"unterminated phrase`, []searchTerm{{text: "unterminated phrase", quoted: true}}},
+		{`- "" " "`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := searchTerms(tt.query); !slices.Equal(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"go generics", `("go" "generics")`},
+		{`"type parameters" go`, `("type parameters" "go")`},
+		{"go or rust", `("go") OR ("rust")`},
+		{"go OR rust -generics", `("go") OR ("rust" NOT "generics")`},
+		{`go -rust -"borrow checker"`, `("go" NOT "rust" NOT "borrow checker")`},
+		{`"or"`, `("or")`},
+		{"c++ AND (x)", `("c++" "AND" "(x)")`},
+		{"-rust", `""`},
+		{"or", `""`},
+		{"", `""`},
+	}
+
+	// Every converted query must be valid FTS5, whatever the user typed.
+	st := newSQLiteStore(t)
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := ftsQuery(tt.query); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if _, err := st.SearchPosts(context.Background(), database.SearchPostsParams{
+				Query: tt.query, Now: time.Now(), UserID: uuid.New(), Limit: 10,
+			}); err != nil {
+				t.Errorf("searching failed: %v", err)
+			}
+		})
+	}
+}
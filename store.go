@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"internal/database"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store holds the users, feeds, follows, posts and reads that the commands work on. It
+// is implemented by sqlStore on PostgreSQL or SQLite, and by memStore in tests.
+type Store interface {
+	CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error)
+	GetUser(ctx context.Context, name string) (database.User, error)
+	GetUsers(ctx context.Context) ([]database.User, error)
+	ResetUsers(ctx context.Context) error
+
+	CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error)
+	GetFeed(ctx context.Context, url string) (database.Feed, error)
+	GetFeeds(ctx context.Context) ([]database.GetFeedsRow, error)
+	GetEnabledFeeds(ctx context.Context) ([]database.Feed, error)
+	GetBrokenFeeds(ctx context.Context) ([]database.Feed, error)
+	EnableFeed(ctx context.Context, arg database.EnableFeedParams) (database.Feed, error)
+	ClaimNextFeedToFetch(ctx context.Context, arg database.ClaimNextFeedToFetchParams) (database.Feed, error)
+	UpdateFeedCache(ctx context.Context, arg database.UpdateFeedCacheParams) error
+	UpdateFeedSchedule(ctx context.Context, arg database.UpdateFeedScheduleParams) error
+	MarkFeedFetched(ctx context.Context, arg database.MarkFeedFetchedParams) error
+	RecordFeedSuccess(ctx context.Context, arg database.RecordFeedSuccessParams) error
+	RecordFeedFailure(ctx context.Context, arg database.RecordFeedFailureParams) (database.Feed, error)
+	CreateFeedFetch(ctx context.Context, arg database.CreateFeedFetchParams) error
+	GetFeedHealth(ctx context.Context, startedAt time.Time) ([]database.GetFeedHealthRow, error)
+
+	CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error)
+	GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFeedFollowsForUserRow, error)
+	DeleteFollow(ctx context.Context, arg database.DeleteFollowParams) error
+
+	CreatePost(ctx context.Context, arg database.CreatePostParams) (database.CreatePostRow, error)
+	CreatePosts(ctx context.Context, arg database.CreatePostsParams) ([]database.CreatePostsRow, error)
+	GetPosts(ctx context.Context, arg database.GetPostsParams) ([]database.Post, error)
+	SearchPosts(ctx context.Context, arg database.SearchPostsParams) ([]database.SearchPostsRow, error)
+	GetUnreadPosts(ctx context.Context, arg database.GetUnreadPostsParams) ([]database.Post, error)
+	MarkPostRead(ctx context.Context, arg database.MarkPostReadParams) (int64, error)
+	MarkAllPostsRead(ctx context.Context, arg database.MarkAllPostsReadParams) (int64, error)
+
+	// InTx runs fn with a store whose changes are committed together if fn
+	// succeeds and discarded otherwise.
+	InTx(ctx context.Context, fn func(tx Store) error) error
+	// Savepoint runs fn within a transaction started by InTx, discarding the
+	// changes made by fn if it fails without aborting the transaction.
+	Savepoint(ctx context.Context, fn func() error) error
+}
+
+// sqlStore runs the sqlc queries on a database connection or on one of its
+// transactions.
+type sqlStore struct {
+	*database.Queries
+	conn    *sql.DB
+	tx      *sql.Tx
+	dialect string
+}
+
+func newSQLStore(conn *sql.DB, dialect string) *sqlStore {
+	return &sqlStore{
+		Queries: newQueries(dialect, conn),
+		conn:    conn,
+		dialect: dialect,
+	}
+}
+
+// newQueries returns the queries for db, which is either the connection or one of
+// its transactions.
+func newQueries(dialect string, db database.DBTX) *database.Queries {
+	if dialect == dialectSQLite {
+		return database.New(sqliteDB{db: db})
+	}
+	return database.New(db)
+}
+
+func (s *sqlStore) InTx(ctx context.Context, fn func(tx Store) error) error {
+	if s.tx != nil {
+		return fn(s)
+	}
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(&sqlStore{
+		Queries: newQueries(s.dialect, tx),
+		conn:    s.conn,
+		tx:      tx,
+		dialect: s.dialect,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *sqlStore) Savepoint(ctx context.Context, fn func() error) error {
+	if s.tx == nil {
+		return fn()
+	}
+
+	if _, err := s.tx.ExecContext(ctx, "SAVEPOINT store"); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		if _, rollbackErr := s.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT store"); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	_, err := s.tx.ExecContext(ctx, "RELEASE SAVEPOINT store")
+	return err
+}